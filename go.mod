@@ -2,4 +2,8 @@ module currencyservice
 
 go 1.23.4
 
-require github.com/mattn/go-sqlite3 v1.14.24 // indirect
+require (
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.24
+	github.com/shopspring/decimal v1.4.0
+)
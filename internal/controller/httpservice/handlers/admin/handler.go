@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
+	"currencyservice/internal/usecase/ratesync"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const defaultProviderRunsLimit = 50
+
+type Handler struct {
+	rateSyncUsecase *ratesync.Usecase
+}
+
+func NewHandler(rateSyncUsecase *ratesync.Usecase) *Handler {
+	return &Handler{
+		rateSyncUsecase: rateSyncUsecase,
+	}
+}
+
+// GET /admin/providerRuns?provider=ecb&limit=10
+func (h Handler) GetProviderRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit := defaultProviderRunsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limitValue, err := strconv.Atoi(value)
+		if err != nil || limitValue <= 0 {
+			http.Error(w, "Invalid limit format", http.StatusBadRequest)
+			return
+		}
+		limit = limitValue
+	}
+
+	runs, err := h.rateSyncUsecase.GetProviderRuns(r.Context(), r.URL.Query().Get("provider"), limit)
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// GET /admin/consensus/USDRUB
+func (h Handler) GetRateConsensus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	code := r.URL.Path[len("/admin/consensus/"):]
+	if len(code) != 6 {
+		http.Error(w, "Both base and target currency codes are required", http.StatusBadRequest)
+		return
+	}
+
+	consensus, err := h.rateSyncUsecase.GetRateConsensus(r.Context(), code[:3], code[3:])
+	if err != nil {
+		if errors.Is(err, models.ErrorConsensusNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(consensus)
+}
+
+// POST /admin/providerRuns with provider=ecb runs the provider right away.
+func (h Handler) RunProvider(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	provider := r.FormValue("provider")
+	if provider == "" {
+		http.Error(w, "Field provider is required", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.rateSyncUsecase.RunProvider(r.Context(), provider)
+	if err != nil {
+		if errors.Is(err, models.ErrorProviderNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
@@ -0,0 +1,108 @@
+package exchanges
+
+import (
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+const maxBatchSize = 10000
+
+// batchExchangeItem is one conversion of a batch, with the same fields as
+// the query of GET /exchange.
+type batchExchangeItem struct {
+	From     string           `json:"from"`
+	To       string           `json:"to"`
+	Amount   *decimal.Decimal `json:"amount"`
+	Date     string           `json:"date"`
+	Rounding string           `json:"rounding"`
+	Side     string           `json:"side"`
+	Client   string           `json:"client"`
+}
+
+func (item batchExchangeItem) value(key string) string {
+	switch key {
+	case "from":
+		return item.From
+	case "to":
+		return item.To
+	case "amount":
+		if item.Amount != nil {
+			return item.Amount.String()
+		}
+	case "date":
+		return item.Date
+	case "rounding":
+		return item.Rounding
+	case "side":
+		return item.Side
+	case "client":
+		return item.Client
+	}
+
+	return ""
+}
+
+// POST /exchange/batch
+// Results are returned in the order of the items, each either with the
+// conversion or with the error and status code it failed with.
+func (h Handler) GetExchangeCurrenciesBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var items []batchExchangeItem
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		http.Error(w, "Invalid batch format: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(items) > maxBatchSize {
+		http.Error(w, fmt.Sprintf("A batch holds at most %d conversions", maxBatchSize), http.StatusBadRequest)
+		return
+	}
+
+	response := make([]map[string]interface{}, len(items))
+	requests := make([]models.ExchangeRequest, 0, len(items))
+	positions := make([]int, 0, len(items))
+	for i, item := range items {
+		request, err := parseExchangeRequest(item.value)
+		if err != nil {
+			response[i] = map[string]interface{}{
+				"status": http.StatusBadRequest,
+				"error":  err.Error(),
+			}
+			continue
+		}
+		requests = append(requests, request)
+		positions = append(positions, i)
+	}
+
+	results, err := h.exchangeUsecase.GetExchangeCurrenciesBatch(r.Context(), requests)
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+
+	for i, result := range results {
+		if result.Error != nil {
+			response[positions[i]] = map[string]interface{}{
+				"status": exchangeErrorStatus(result.Error),
+				"error":  result.Error.Error(),
+			}
+			continue
+		}
+		response[positions[i]] = map[string]interface{}{
+			"status": http.StatusOK,
+			"result": result.Result,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,305 @@
+package exchanges
+
+import (
+	"archive/zip"
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	maxImportSize = 32 << 20
+
+	exportTableCurrencies    = "currencies"
+	exportTableExchangeRates = "exchangeRates"
+)
+
+var (
+	currenciesHeader    = []string{"code", "fullname", "sign", "minorUnits", "rounding"}
+	exchangeRatesHeader = []string{"base", "target", "rate", "effectiveAt", "spread"}
+)
+
+// POST /import
+// Accepts multipart files "currencies" (code, fullname, sign[, minorUnits[,
+// rounding]]) and "rates" (base, target, rate[, effectiveAt[, spread]]). With
+// dryRun=true the files are only validated.
+func (h Handler) Import(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxImportSize); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dryRun := false
+	if value := r.FormValue("dryRun"); value != "" {
+		dryRunValue, err := strconv.ParseBool(value)
+		if err != nil {
+			http.Error(w, "Invalid dryRun format", http.StatusBadRequest)
+			return
+		}
+		dryRun = dryRunValue
+	}
+
+	request := models.ImportRequest{}
+	parseErrors := make([]models.ImportRowError, 0)
+	files := 0
+
+	if file, _, err := r.FormFile(models.ImportFileCurrencies); err == nil {
+		rows, errs := parseCurrenciesCSV(file)
+		file.Close()
+		request.Currencies = rows
+		parseErrors = append(parseErrors, errs...)
+		files++
+	} else if !errors.Is(err, http.ErrMissingFile) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if file, _, err := r.FormFile(models.ImportFileRates); err == nil {
+		rows, errs := parseExchangeRatesCSV(file)
+		file.Close()
+		request.Rates = rows
+		parseErrors = append(parseErrors, errs...)
+		files++
+	} else if !errors.Is(err, http.ErrMissingFile) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if files == 0 {
+		http.Error(w, "At least one file (currencies, rates) is required", http.StatusBadRequest)
+		return
+	}
+
+	// Rows that could not be parsed still get validated, but nothing is written.
+	request.DryRun = dryRun || len(parseErrors) > 0
+
+	report, err := h.exchangeUsecase.Import(r.Context(), request)
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+	report.DryRun = dryRun
+	report.Errors = append(parseErrors, report.Errors...)
+
+	status := http.StatusOK
+	if len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// GET /export?format=csv&table=currencies|exchangeRates
+// Without table both tables are returned as CSV files in a zip archive.
+func (h Handler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if format := r.URL.Query().Get("format"); format != "" && format != "csv" {
+		http.Error(w, "Unsupported export format", http.StatusBadRequest)
+		return
+	}
+
+	table := r.URL.Query().Get("table")
+	if table != "" && table != exportTableCurrencies && table != exportTableExchangeRates {
+		http.Error(w, "Unknown table, expected currencies or exchangeRates", http.StatusBadRequest)
+		return
+	}
+
+	currencies, err := h.exchangeUsecase.GetAllCurrencies(r.Context())
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+
+	rates, err := h.exchangeUsecase.GetExchangeRates(r.Context())
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+
+	switch table {
+	case exportTableCurrencies:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="currencies.csv"`)
+		writeCurrenciesCSV(w, currencies)
+	case exportTableExchangeRates:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="exchangeRates.csv"`)
+		writeExchangeRatesCSV(w, rates)
+	default:
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="export.zip"`)
+
+		archive := zip.NewWriter(w)
+		now := time.Now()
+		if file, err := archive.CreateHeader(&zip.FileHeader{Name: "currencies.csv", Method: zip.Deflate, Modified: now}); err == nil {
+			writeCurrenciesCSV(file, currencies)
+		}
+		if file, err := archive.CreateHeader(&zip.FileHeader{Name: "exchangeRates.csv", Method: zip.Deflate, Modified: now}); err == nil {
+			writeExchangeRatesCSV(file, rates)
+		}
+		archive.Close()
+	}
+}
+
+func writeCurrenciesCSV(w io.Writer, currencies []models.Currency) error {
+	writer := csv.NewWriter(w)
+	writer.Write(currenciesHeader)
+	for _, currency := range currencies {
+		writer.Write([]string{
+			currency.Code,
+			currency.FullName,
+			currency.Sign,
+			strconv.Itoa(int(currency.MinorUnits)),
+			string(currency.RoundingMode),
+		})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func writeExchangeRatesCSV(w io.Writer, rates []models.CurrencyExchange) error {
+	writer := csv.NewWriter(w)
+	writer.Write(exchangeRatesHeader)
+	for _, rate := range rates {
+		writer.Write([]string{rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate.String(), "", rate.Spread.String()})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// readCSV calls parse for every record with its line number, skipping an
+// optional header row whose first field is header.
+func readCSV(file io.Reader, name, header string, parse func(line int, record []string) error) []models.ImportRowError {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rowErrors := make([]models.ImportRowError, 0)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, models.ImportRowError{File: name, Line: parseErr.Line, Error: parseErr.Err.Error()})
+				continue
+			}
+			rowErrors = append(rowErrors, models.ImportRowError{File: name, Error: err.Error()})
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), header) {
+			continue
+		}
+
+		if err := parse(line, record); err != nil {
+			rowErrors = append(rowErrors, models.ImportRowError{File: name, Line: line, Error: err.Error()})
+		}
+	}
+
+	return rowErrors
+}
+
+func parseCurrenciesCSV(file io.Reader) ([]models.CurrencyImportRow, []models.ImportRowError) {
+	rows := make([]models.CurrencyImportRow, 0)
+
+	rowErrors := readCSV(file, models.ImportFileCurrencies, "code", func(line int, record []string) error {
+		if len(record) < 3 || len(record) > 5 {
+			return fmt.Errorf("Expected 3 to 5 fields (code, fullname, sign[, minorUnits[, rounding]]), got %d", len(record))
+		}
+
+		code := strings.TrimSpace(record[0])
+		currency := models.Currency{
+			Code:       code,
+			FullName:   strings.TrimSpace(record[1]),
+			Sign:       strings.TrimSpace(record[2]),
+			MinorUnits: models.DefaultMinorUnits(code),
+		}
+
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			minorUnits, err := strconv.ParseInt(strings.TrimSpace(record[3]), 10, 32)
+			if err != nil {
+				return fmt.Errorf("Invalid minorUnits format")
+			}
+			currency.MinorUnits = int32(minorUnits)
+		}
+
+		if len(record) > 4 {
+			currency.RoundingMode = models.RoundingMode(strings.TrimSpace(record[4]))
+		}
+
+		rows = append(rows, models.CurrencyImportRow{Line: line, Currency: currency})
+		return nil
+	})
+
+	return rows, rowErrors
+}
+
+func parseExchangeRatesCSV(file io.Reader) ([]models.ExchangeRateImportRow, []models.ImportRowError) {
+	rows := make([]models.ExchangeRateImportRow, 0)
+
+	rowErrors := readCSV(file, models.ImportFileRates, "base", func(line int, record []string) error {
+		if len(record) < 3 || len(record) > 5 {
+			return fmt.Errorf("Expected 3 to 5 fields (base, target, rate[, effectiveAt[, spread]]), got %d", len(record))
+		}
+
+		rate, err := decimal.NewFromString(strings.TrimSpace(record[2]))
+		if err != nil {
+			return fmt.Errorf("Invalid rate format")
+		}
+
+		row := models.ExchangeRateImportRow{
+			Line:               line,
+			BaseCurrencyCode:   strings.TrimSpace(record[0]),
+			TargetCurrencyCode: strings.TrimSpace(record[1]),
+			Rate:               rate,
+		}
+
+		if len(record) > 3 && strings.TrimSpace(record[3]) != "" {
+			row.EffectiveAt, err = parseTime(strings.TrimSpace(record[3]))
+			if err != nil {
+				return fmt.Errorf("Invalid effectiveAt format")
+			}
+		}
+
+		if len(record) > 4 && strings.TrimSpace(record[4]) != "" {
+			spread, err := decimal.NewFromString(strings.TrimSpace(record[4]))
+			if err != nil {
+				return fmt.Errorf("Invalid spread format")
+			}
+			row.Spread = decimal.NewNullDecimal(spread)
+		}
+
+		rows = append(rows, row)
+		return nil
+	})
+
+	return rows, rowErrors
+}
@@ -0,0 +1,205 @@
+package exchanges
+
+import (
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// GET /feeRules
+func (h Handler) GetFeeRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rules, err := h.exchangeUsecase.GetFeeRules(r.Context())
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// GET /feeRules/1
+func (h Handler) GetFeeRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Path[len("/feeRules/"):])
+	if err != nil {
+		http.Error(w, "Invalid fee rule id", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.exchangeUsecase.GetFeeRule(r.Context(), id)
+	if err != nil {
+		writeFeeRuleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// POST /feeRules
+func (h Handler) CreateFeeRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule := models.FeeRule{}
+	if err := parseFeeRule(r, &rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.exchangeUsecase.CreateFeeRule(r.Context(), rule)
+	if err != nil {
+		writeFeeRuleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// PATCH /feeRules/1
+// Only the fields present in the form change; an empty value clears a code,
+// the client or the upper bound of the amount tier.
+func (h Handler) UpdateFeeRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Path[len("/feeRules/"):])
+	if err != nil {
+		http.Error(w, "Invalid fee rule id", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.exchangeUsecase.GetFeeRule(r.Context(), id)
+	if err != nil {
+		writeFeeRuleError(w, err)
+		return
+	}
+
+	if err := parseFeeRule(r, &rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exchangeUsecase.UpdateFeeRule(r.Context(), rule); err != nil {
+		writeFeeRuleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DELETE /feeRules/1
+func (h Handler) DeleteFeeRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Path[len("/feeRules/"):])
+	if err != nil {
+		http.Error(w, "Invalid fee rule id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exchangeUsecase.DeleteFeeRule(r.Context(), id); err != nil {
+		writeFeeRuleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Fee rule deleted successfully",
+		"id":      id,
+	})
+}
+
+func writeFeeRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrorFeeRuleNotFound), errors.Is(err, models.ErrorCurrencyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrorInvalidFeeRule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		httperror.Write(w, err)
+	}
+}
+
+// parseFeeRule overrides the fields of the rule that are present in the form.
+func parseFeeRule(r *http.Request, rule *models.FeeRule) error {
+	if values, ok := r.Form["base"]; ok {
+		rule.BaseCurrencyCode = values[0]
+	}
+	if values, ok := r.Form["target"]; ok {
+		rule.TargetCurrencyCode = values[0]
+	}
+	if values, ok := r.Form["client"]; ok {
+		rule.ClientID = values[0]
+	}
+
+	amounts := []struct {
+		field string
+		value *decimal.Decimal
+	}{
+		{"minAmount", &rule.MinAmount},
+		{"markupPercent", &rule.MarkupPercent},
+		{"fixedFee", &rule.FixedFee},
+	}
+	for _, amount := range amounts {
+		values, ok := r.Form[amount.field]
+		if !ok {
+			continue
+		}
+
+		value, err := decimal.NewFromString(values[0])
+		if err != nil {
+			return fmt.Errorf("Invalid %s format", amount.field)
+		}
+		*amount.value = value
+	}
+
+	if values, ok := r.Form["maxAmount"]; ok {
+		rule.MaxAmount = decimal.NullDecimal{}
+		if values[0] != "" {
+			value, err := decimal.NewFromString(values[0])
+			if err != nil {
+				return errors.New("Invalid maxAmount format")
+			}
+			rule.MaxAmount = decimal.NewNullDecimal(value)
+		}
+	}
+
+	return nil
+}
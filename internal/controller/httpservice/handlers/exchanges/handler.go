@@ -1,11 +1,18 @@
 package exchanges
 
 import (
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
 	"currencyservice/internal/usecase/exchangerate"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Handler struct {
@@ -24,9 +31,9 @@ func (h Handler) GetCurrencies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currencies, err := h.exchangeUsecase.GetAllCurrencies()
+	currencies, err := h.exchangeUsecase.GetAllCurrencies(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		httperror.Write(w, err)
 		return
 	}
 
@@ -46,9 +53,9 @@ func (h Handler) GetCurrencyByCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currency, err := h.exchangeUsecase.GetCurrency(code)
+	currency, err := h.exchangeUsecase.GetCurrency(r.Context(), code)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeExchangeError(w, err)
 		return
 	}
 
@@ -76,8 +83,38 @@ func (h Handler) CreateNewCurrency(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.exchangeUsecase.CreateNewCurrency(code, fullName, sign); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	currency := models.Currency{
+		Code:         code,
+		FullName:     fullName,
+		Sign:         sign,
+		MinorUnits:   models.DefaultMinorUnits(code),
+		RoundingMode: models.DefaultRoundingMode,
+	}
+
+	if minorUnits := r.FormValue("minorUnits"); minorUnits != "" {
+		minorUnitsValue, err := strconv.ParseInt(minorUnits, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid minorUnits format", http.StatusBadRequest)
+			return
+		}
+		currency.MinorUnits = int32(minorUnitsValue)
+	}
+
+	if rounding := r.FormValue("rounding"); rounding != "" {
+		roundingMode, err := models.ParseRoundingMode(rounding)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		currency.RoundingMode = roundingMode
+	}
+
+	if err := h.exchangeUsecase.CreateNewCurrency(r.Context(), currency); err != nil {
+		if errors.Is(err, models.ErrorCurrencyAlreadyExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		httperror.Write(w, err)
 		return
 	}
 
@@ -95,24 +132,23 @@ func (h Handler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rates, err := h.exchangeUsecase.GetExchangeRates()
+	rates, err := h.exchangeUsecase.GetExchangeRates(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		httperror.Write(w, err)
 		return
 	}
 
 	response := make([]map[string]interface{}, 0, len(rates))
 	for _, rate := range rates {
-		fmt.Println(rate)
-		baseCurrency, err := h.exchangeUsecase.GetCurrency(rate.BaseCurrencyCode)
+		baseCurrency, err := h.exchangeUsecase.GetCurrency(r.Context(), rate.BaseCurrencyCode)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httperror.Write(w, err)
 			return
 		}
 
-		targetCurrency, err := h.exchangeUsecase.GetCurrency(rate.TargetCurrencyCode)
+		targetCurrency, err := h.exchangeUsecase.GetCurrency(r.Context(), rate.TargetCurrencyCode)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			httperror.Write(w, err)
 			return
 		}
 
@@ -130,7 +166,11 @@ func (h Handler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
 				"code": targetCurrency.Code,
 				"sign": targetCurrency.Sign,
 			},
-			"rate": rate.Rate,
+			"rate":      rate.Rate,
+			"bid":       rate.Bid(),
+			"ask":       rate.Ask(),
+			"spread":    rate.Spread,
+			"updatedAt": rate.UpdatedAt,
 		})
 	}
 
@@ -161,9 +201,9 @@ func (h Handler) GetExchangeRateByCodesPair(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	rate, err := h.exchangeUsecase.GetExchangeRateByCodesPair(base, target)
+	rate, err := h.exchangeUsecase.GetExchangeRateByCodesPair(r.Context(), base, target)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeExchangeError(w, err)
 		return
 	}
 
@@ -184,30 +224,56 @@ func (h Handler) CreateExchangeRate(w http.ResponseWriter, r *http.Request) {
 
 	base := r.FormValue("base")
 	target := r.FormValue("target")
-	rate := r.FormValue("rate")
 
-	if base == "" || target == "" || rate == "" {
+	if base == "" || target == "" {
 		http.Error(w, "All fields (base, target, rate) are required", http.StatusBadRequest)
 		return
 	}
 
-	rateValue, err := strconv.ParseFloat(rate, 64)
+	rateValue, spread, err := parseRate(r, "rate")
 	if err != nil {
-		http.Error(w, "Invalid rate format", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if err := h.exchangeUsecase.CreateExchangeRate(base, target, rateValue); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if effectiveAt := r.FormValue("effectiveAt"); effectiveAt != "" {
+		effectiveAtValue, err := parseTime(effectiveAt)
+		if err != nil {
+			http.Error(w, "Invalid effectiveAt format", http.StatusBadRequest)
+			return
+		}
+
+		if spread.Valid {
+			http.Error(w, "Spreads cannot be scheduled", http.StatusBadRequest)
+			return
+		}
+
+		scheduled, err := h.exchangeUsecase.ScheduleNewExchangeRate(r.Context(), base, target, rateValue, effectiveAtValue)
+		if err != nil {
+			writeExchangeRateError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(scheduled)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
+	if err := h.exchangeUsecase.CreateExchangeRate(r.Context(), base, target, rateValue, spread.Decimal); err != nil {
+		writeExchangeRateError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
 		"message": "Exchange rate created successfully",
 		"pair":    base + "/" + target,
-	})
+	}
+	h.addInconsistencies(r.Context(), response, base, target)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
 }
 
 func (h Handler) UpdateExchangeRate(w http.ResponseWriter, r *http.Request) {
@@ -235,29 +301,49 @@ func (h Handler) UpdateExchangeRate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newRate := r.FormValue("newRate")
-
-	if newRate == "" {
-		http.Error(w, "Аield newRate are required", http.StatusBadRequest)
+	rateValue, spread, err := parseRate(r, "newRate")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	rateValue, err := strconv.ParseFloat(newRate, 64)
-	if err != nil {
-		http.Error(w, "Invalid rate format", http.StatusBadRequest)
+	if effectiveAt := r.FormValue("effectiveAt"); effectiveAt != "" {
+		effectiveAtValue, err := parseTime(effectiveAt)
+		if err != nil {
+			http.Error(w, "Invalid effectiveAt format", http.StatusBadRequest)
+			return
+		}
+
+		if spread.Valid {
+			http.Error(w, "Spreads cannot be scheduled", http.StatusBadRequest)
+			return
+		}
+
+		scheduled, err := h.exchangeUsecase.ScheduleExchangeRateUpdate(r.Context(), base, target, rateValue, effectiveAtValue)
+		if err != nil {
+			writeExchangeRateError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(scheduled)
 		return
 	}
 
-	if err := h.exchangeUsecase.UpdateExchangeRate(base, target, rateValue); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.exchangeUsecase.UpdateExchangeRate(r.Context(), base, target, rateValue, spread); err != nil {
+		writeExchangeRateError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{
+	response := map[string]interface{}{
 		"message": "Exchange rate updated successfully",
 		"pair":    base + "/" + target,
-	})
+	}
+	h.addInconsistencies(r.Context(), response, base, target)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
 }
 
 func (h Handler) GetExchangeCurrencies(w http.ResponseWriter, r *http.Request) {
@@ -271,27 +357,278 @@ func (h Handler) GetExchangeCurrencies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	from := r.FormValue("from")
-	to := r.FormValue("to")
-	amount := r.FormValue("amount")
+	request, err := parseExchangeRequest(r.FormValue)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	if from == "" || to == "" || amount == "" {
-		http.Error(w, "All fields (from, to, amount) are required", http.StatusBadRequest)
+	result, err := h.exchangeUsecase.GetExchangeCurrencies(r.Context(), request)
+	if err != nil {
+		writeExchangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h Handler) GetExchangeRateHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	base, target, ok := pairFromPath(r.URL.Path, "/exchangeRate/", "/history")
+	if !ok {
+		http.Error(w, "Both base and target currency codes are required", http.StatusBadRequest)
 		return
 	}
 
-	amountValue, err := strconv.ParseFloat(amount, 64)
+	from, to, err := parseTimeRange(r)
 	if err != nil {
-		http.Error(w, "Invalid rate format", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	result, err := h.exchangeUsecase.GetExchangeCurrencies(from, to, amountValue)
+	history, err := h.exchangeUsecase.GetExchangeRateHistory(r.Context(), base, target, from, to)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeExchangeError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(history)
+}
+
+func (h Handler) GetExchangeRateOHLC(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	base, target, ok := pairFromPath(r.URL.Path, "/exchangeRate/", "/ohlc")
+	if !ok {
+		http.Error(w, "Both base and target currency codes are required", http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseTimeRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	interval := 24 * time.Hour
+	if value := r.URL.Query().Get("interval"); value != "" {
+		interval, err = parseInterval(value)
+		if err != nil {
+			http.Error(w, "Invalid interval format", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ohlc, err := h.exchangeUsecase.GetExchangeRateOHLC(r.Context(), base, target, from, to, interval)
+	if err != nil {
+		if errors.Is(err, models.ErrorInvalidInterval) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeExchangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ohlc)
+}
+
+// parseInterval extends time.ParseDuration with day (1d) and week (1w) units.
+func parseInterval(value string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if count, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid interval %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	return time.ParseDuration(value)
+}
+
+// pairFromPath extracts the six letter currency pair, e.g. USDRUB, that sits
+// between prefix and suffix in the request path.
+func pairFromPath(path, prefix, suffix string) (string, string, bool) {
+	code := strings.TrimSuffix(strings.TrimPrefix(path, prefix), suffix)
+	if len(code) != 6 {
+		return "", "", false
+	}
+
+	return code[:3], code[3:], true
+}
+
+// parseTime accepts either an RFC 3339 timestamp or a plain date.
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+// parseTimeRange reads the optional from and to query parameters, which
+// default to the beginning of time and now respectively.
+func parseTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	from := time.Unix(0, 0)
+	to := time.Now()
+
+	if value := r.URL.Query().Get("from"); value != "" {
+		t, err := parseTime(value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid from format")
+		}
+		from = t
+	}
+
+	if value := r.URL.Query().Get("to"); value != "" {
+		t, err := parseTime(value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("Invalid to format")
+		}
+		to = t
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("from must not be after to")
+	}
+
+	return from, to, nil
+}
+
+// parseRate reads a mid rate from the rateField form value with an optional
+// spread, or derives both from bid and ask. The spread is invalid when none
+// was given.
+func parseRate(r *http.Request, rateField string) (decimal.Decimal, decimal.NullDecimal, error) {
+	bid, ask := r.FormValue("bid"), r.FormValue("ask")
+	if bid != "" || ask != "" {
+		if r.FormValue(rateField) != "" || r.FormValue("spread") != "" {
+			return decimal.Decimal{}, decimal.NullDecimal{}, fmt.Errorf("Either %s or bid and ask are allowed", rateField)
+		}
+
+		bidValue, err := decimal.NewFromString(bid)
+		if err != nil || !bidValue.IsPositive() {
+			return decimal.Decimal{}, decimal.NullDecimal{}, errors.New("Invalid bid format")
+		}
+
+		askValue, err := decimal.NewFromString(ask)
+		if err != nil || askValue.LessThan(bidValue) {
+			return decimal.Decimal{}, decimal.NullDecimal{}, errors.New("Invalid ask format")
+		}
+
+		mid := bidValue.Add(askValue).Div(decimal.NewFromInt(2))
+		return mid, decimal.NewNullDecimal(askValue.Sub(bidValue)), nil
+	}
+
+	rate := r.FormValue(rateField)
+	if rate == "" {
+		return decimal.Decimal{}, decimal.NullDecimal{}, fmt.Errorf("Field %s or bid and ask are required", rateField)
+	}
+
+	rateValue, err := decimal.NewFromString(rate)
+	if err != nil || !rateValue.IsPositive() {
+		return decimal.Decimal{}, decimal.NullDecimal{}, errors.New("Invalid rate format")
+	}
+
+	var spread decimal.NullDecimal
+	if spreadParam := r.FormValue("spread"); spreadParam != "" {
+		spreadValue, err := decimal.NewFromString(spreadParam)
+		if err != nil {
+			return decimal.Decimal{}, decimal.NullDecimal{}, errors.New("Invalid spread format")
+		}
+		spread = decimal.NewNullDecimal(spreadValue)
+	}
+
+	return rateValue, spread, nil
+}
+
+// parseExchangeRequest reads a conversion from the from, to, amount, rounding,
+// side, date and client values, e.g. r.FormValue.
+func parseExchangeRequest(value func(key string) string) (models.ExchangeRequest, error) {
+	from := value("from")
+	to := value("to")
+	amount := value("amount")
+
+	if from == "" || to == "" || amount == "" {
+		return models.ExchangeRequest{}, errors.New("All fields (from, to, amount) are required")
+	}
+
+	amountValue, err := decimal.NewFromString(amount)
+	if err != nil {
+		return models.ExchangeRequest{}, errors.New("Invalid rate format")
+	}
+
+	var rounding models.RoundingMode
+	if roundingParam := value("rounding"); roundingParam != "" {
+		rounding, err = models.ParseRoundingMode(roundingParam)
+		if err != nil {
+			return models.ExchangeRequest{}, err
+		}
+	}
+
+	side := models.SideMid
+	if sideParam := value("side"); sideParam != "" {
+		side, err = models.ParseSide(sideParam)
+		if err != nil {
+			return models.ExchangeRequest{}, err
+		}
+	}
+
+	var date time.Time
+	if dateParam := value("date"); dateParam != "" {
+		date, err = parseTime(dateParam)
+		if err != nil {
+			return models.ExchangeRequest{}, errors.New("Invalid date format")
+		}
+	}
+
+	return models.ExchangeRequest{
+		BaseCurrencyCode:   from,
+		TargetCurrencyCode: to,
+		Amount:             amountValue,
+		RoundingMode:       rounding,
+		Date:               date,
+		Side:               side,
+		ClientID:           value("client"),
+	}, nil
+}
+
+// writeExchangeRateError maps the errors of a rate write to a status code.
+func writeExchangeRateError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrorInvalidSpread):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrorCurrencyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrorInverseRateConflict), errors.Is(err, models.ErrorExchangeRateAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperror.Write(w, err)
+	}
+}
+
+func writeExchangeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), exchangeErrorStatus(err))
+}
+
+// exchangeErrorStatus maps the errors of a conversion to a status code.
+func exchangeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrorCurrencyNotFound), errors.Is(err, models.ErrorExchangeRateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrorExchangeRateStale), errors.Is(err, models.ErrorFeeExceedsAmount):
+		return http.StatusUnprocessableEntity
+	default:
+		return httperror.Status(err)
+	}
 }
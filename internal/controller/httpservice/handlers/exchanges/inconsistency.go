@@ -0,0 +1,49 @@
+package exchanges
+
+import (
+	"context"
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"encoding/json"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// GET /exchangeRates/inconsistencies[?tolerance=0.001]
+func (h Handler) GetExchangeRateInconsistencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var tolerance decimal.NullDecimal
+	if toleranceParam := r.URL.Query().Get("tolerance"); toleranceParam != "" {
+		value, err := decimal.NewFromString(toleranceParam)
+		if err != nil || value.IsNegative() {
+			http.Error(w, "Invalid tolerance format", http.StatusBadRequest)
+			return
+		}
+		tolerance = decimal.NewNullDecimal(value)
+	}
+
+	inconsistencies, err := h.exchangeUsecase.GetExchangeRateInconsistencies(r.Context(), tolerance)
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(inconsistencies)
+}
+
+// addInconsistencies adds the inconsistencies a write of the pair caused to
+// the response, when checks on write are enabled and found any. The check is
+// advisory, so a failing check does not fail the write that already happened.
+func (h Handler) addInconsistencies(ctx context.Context, response map[string]interface{}, base, target string) {
+	inconsistencies, err := h.exchangeUsecase.CheckExchangeRateWrite(ctx, base, target)
+	if err != nil || len(inconsistencies) == 0 {
+		return
+	}
+
+	response["inconsistencies"] = inconsistencies
+}
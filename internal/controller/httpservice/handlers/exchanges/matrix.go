@@ -0,0 +1,42 @@
+package exchanges
+
+import (
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// GET /exchangeRates/matrix?currencies=USD,EUR,GBP
+func (h Handler) GetExchangeRateMatrix(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var codes []string
+	seen := make(map[string]bool)
+	for _, code := range strings.Split(r.URL.Query().Get("currencies"), ",") {
+		code = strings.TrimSpace(code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+
+	matrix, err := h.exchangeUsecase.GetExchangeRateMatrix(r.Context(), codes)
+	if err != nil {
+		if errors.Is(err, models.ErrorCurrencyNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matrix)
+}
@@ -0,0 +1,104 @@
+package exchanges
+
+import (
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// POST /quotes
+func (h Handler) CreateQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if r.FormValue("date") != "" {
+		http.Error(w, "Quotes are only given at the current rates", http.StatusBadRequest)
+		return
+	}
+
+	request, err := parseExchangeRequest(r.FormValue)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.exchangeUsecase.CreateQuote(r.Context(), request)
+	if err != nil {
+		writeExchangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(quote)
+}
+
+// GET /quotes/1
+func (h Handler) GetQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Path[len("/quotes/"):])
+	if err != nil {
+		http.Error(w, "Invalid quote id", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.exchangeUsecase.GetQuote(r.Context(), id)
+	if err != nil {
+		writeQuoteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+// POST /quotes/1/execute
+func (h Handler) ExecuteQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimSuffix(r.URL.Path[len("/quotes/"):], "/execute"))
+	if err != nil {
+		http.Error(w, "Invalid quote id", http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.exchangeUsecase.ExecuteQuote(r.Context(), id)
+	if err != nil {
+		writeQuoteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quote)
+}
+
+func writeQuoteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrorQuoteNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrorQuoteExpired):
+		http.Error(w, err.Error(), http.StatusGone)
+	case errors.Is(err, models.ErrorQuoteAlreadyExecuted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperror.Write(w, err)
+	}
+}
@@ -0,0 +1,67 @@
+package exchanges
+
+import (
+	"currencyservice/internal/controller/httpservice/handlers/httperror"
+	"currencyservice/internal/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GET /exchangeRates/scheduled and GET /exchangeRate/USDRUB/scheduled
+func (h Handler) GetScheduledExchangeRates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var base, target string
+	if strings.HasPrefix(r.URL.Path, "/exchangeRate/") {
+		var ok bool
+		base, target, ok = pairFromPath(r.URL.Path, "/exchangeRate/", "/scheduled")
+		if !ok {
+			http.Error(w, "Both base and target currency codes are required", http.StatusBadRequest)
+			return
+		}
+	}
+
+	scheduledRates, err := h.exchangeUsecase.GetScheduledExchangeRates(r.Context(), base, target)
+	if err != nil {
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(scheduledRates)
+}
+
+// DELETE /exchangeRates/scheduled/1
+func (h Handler) CancelScheduledExchangeRate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Path[len("/exchangeRates/scheduled/"):])
+	if err != nil {
+		http.Error(w, "Invalid scheduled rate id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exchangeUsecase.CancelScheduledExchangeRate(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrorScheduledRateNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		httperror.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Scheduled exchange rate cancelled successfully",
+		"id":      id,
+	})
+}
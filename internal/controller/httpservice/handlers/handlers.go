@@ -1,16 +1,20 @@
 package handlers
 
 import (
+	"currencyservice/internal/controller/httpservice/handlers/admin"
 	"currencyservice/internal/controller/httpservice/handlers/exchanges"
 	"currencyservice/internal/usecase/exchangerate"
+	"currencyservice/internal/usecase/ratesync"
 )
 
 type Handlers struct {
 	ExchangesHandler *exchanges.Handler
+	AdminHandler     *admin.Handler
 }
 
-func New(exchangeUsecase *exchangerate.Usecase) *Handlers {
+func New(exchangeUsecase *exchangerate.Usecase, rateSyncUsecase *ratesync.Usecase) *Handlers {
 	return &Handlers{
 		ExchangesHandler: exchanges.NewHandler(exchangeUsecase),
+		AdminHandler:     admin.NewHandler(rateSyncUsecase),
 	}
 }
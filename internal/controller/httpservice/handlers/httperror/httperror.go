@@ -0,0 +1,26 @@
+// Package httperror reports the errors no handler maps to a status of its own.
+package httperror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Status returns 504 for an operation abandoned because the request deadline
+// passed, 503 for one abandoned because the request was cancelled and 500 for
+// any other error.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func Write(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), Status(err))
+}
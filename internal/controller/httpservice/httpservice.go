@@ -1,24 +1,68 @@
 package httpservice
 
 import (
+	"context"
 	"currencyservice/internal/controller/httpservice/handlers"
 	"currencyservice/internal/usecase/exchangerate"
+	"currencyservice/internal/usecase/ratesync"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type Server struct {
 	handlers *handlers.Handlers
+	// requestTimeout bounds every request but the long running ones,
+	// longRequestTimeout bounds those; 0 leaves requests unbounded.
+	requestTimeout     time.Duration
+	longRequestTimeout time.Duration
 }
 
-func NewServer(exchangeUsecase *exchangerate.Usecase) *Server {
+func NewServer(exchangeUsecase *exchangerate.Usecase, rateSyncUsecase *ratesync.Usecase, requestTimeout, longRequestTimeout time.Duration) *Server {
 	return &Server{
-		handlers: handlers.New(exchangeUsecase),
+		handlers:           handlers.New(exchangeUsecase, rateSyncUsecase),
+		requestTimeout:     requestTimeout,
+		longRequestTimeout: longRequestTimeout,
 	}
 }
 
 func (s Server) Start(port uint16) error {
-	return http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+	return http.ListenAndServe(fmt.Sprintf(":%d", port), s.withTimeout(http.DefaultServeMux))
+}
+
+// withTimeout cancels the context of a request once the request timeout
+// passes, which aborts the queries it is waiting on. The handler still answers
+// the request, with the status its error maps to. Imports, exports and
+// provider runs work through whole tables or remote APIs, so they get the
+// longer timeout of their own.
+func (s Server) withTimeout(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := s.requestTimeout
+		if isLongRunning(r) {
+			timeout = s.longRequestTimeout
+		}
+		if timeout <= 0 {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func isLongRunning(r *http.Request) bool {
+	switch r.URL.Path {
+	case "/import", "/export":
+		return true
+	case "/admin/providerRuns":
+		return r.Method == http.MethodPost
+	default:
+		return false
+	}
 }
 
 func (s Server) SetupRoutes() {
@@ -44,7 +88,23 @@ func (s Server) SetupRoutes() {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
+	http.HandleFunc("/exchangeRates/matrix", s.handlers.ExchangesHandler.GetExchangeRateMatrix)
+	http.HandleFunc("/exchangeRates/inconsistencies", s.handlers.ExchangesHandler.GetExchangeRateInconsistencies)
+	http.HandleFunc("/exchangeRates/scheduled", s.handlers.ExchangesHandler.GetScheduledExchangeRates)
+	http.HandleFunc("/exchangeRates/scheduled/", s.handlers.ExchangesHandler.CancelScheduledExchangeRate)
 	http.HandleFunc("/exchangeRate/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/history"):
+			s.handlers.ExchangesHandler.GetExchangeRateHistory(w, r)
+			return
+		case strings.HasSuffix(r.URL.Path, "/ohlc"):
+			s.handlers.ExchangesHandler.GetExchangeRateOHLC(w, r)
+			return
+		case strings.HasSuffix(r.URL.Path, "/scheduled"):
+			s.handlers.ExchangesHandler.GetScheduledExchangeRates(w, r)
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			s.handlers.ExchangesHandler.GetExchangeRateByCodesPair(w, r)
@@ -56,5 +116,53 @@ func (s Server) SetupRoutes() {
 	})
 
 	http.HandleFunc("/exchange", s.handlers.ExchangesHandler.GetExchangeCurrencies)
+	http.HandleFunc("/exchange/batch", s.handlers.ExchangesHandler.GetExchangeCurrenciesBatch)
+
+	http.HandleFunc("/quotes", s.handlers.ExchangesHandler.CreateQuote)
+	http.HandleFunc("/quotes/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/execute") {
+			s.handlers.ExchangesHandler.ExecuteQuote(w, r)
+			return
+		}
+		s.handlers.ExchangesHandler.GetQuote(w, r)
+	})
+
+	http.HandleFunc("/feeRules", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s.handlers.ExchangesHandler.GetFeeRules(w, r)
+		case http.MethodPost:
+			s.handlers.ExchangesHandler.CreateFeeRule(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/feeRules/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s.handlers.ExchangesHandler.GetFeeRule(w, r)
+		case http.MethodPatch:
+			s.handlers.ExchangesHandler.UpdateFeeRule(w, r)
+		case http.MethodDelete:
+			s.handlers.ExchangesHandler.DeleteFeeRule(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	http.HandleFunc("/import", s.handlers.ExchangesHandler.Import)
+	http.HandleFunc("/export", s.handlers.ExchangesHandler.Export)
+
+	http.HandleFunc("/admin/providerRuns", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			s.handlers.AdminHandler.GetProviderRuns(w, r)
+		case http.MethodPost:
+			s.handlers.AdminHandler.RunProvider(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	http.HandleFunc("/admin/consensus/", s.handlers.AdminHandler.GetRateConsensus)
 
 }
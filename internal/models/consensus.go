@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrorConsensusNotFound = errors.New("Consensus rate Not Found")
+	ErrorNoConsensus       = errors.New("Provider quotes deviate too much to agree on a rate")
+	ErrorInvalidConsensus  = errors.New("Invalid consensus method")
+)
+
+type ConsensusMethod string
+
+const (
+	// ConsensusMedian takes the median of the accepted quotes.
+	ConsensusMedian ConsensusMethod = "median"
+	// ConsensusWeighted averages the accepted quotes weighted by provider priority.
+	ConsensusWeighted ConsensusMethod = "weighted"
+)
+
+// ProviderQuote is the latest rate a provider reported for a pair effective
+// at EffectiveAt, zero for a rate effective immediately.
+type ProviderQuote struct {
+	Provider           string
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	EffectiveAt        time.Time
+	ObservedAt         time.Time
+}
+
+// ConsensusContribution is a provider quote as considered for a consensus,
+// expressed in the consensus pair's direction. Deviation is the relative
+// distance from the median of all quotes.
+type ConsensusContribution struct {
+	Provider   string
+	Rate       decimal.Decimal
+	Weight     int
+	Deviation  decimal.Decimal
+	Accepted   bool
+	ObservedAt time.Time
+}
+
+// RateConsensus is the rate agreed on by the providers reporting a pair for
+// the moment it takes effect. Quotes deviating more than MaxDeviation from
+// the median are rejected.
+type RateConsensus struct {
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	EffectiveAt        time.Time
+	Method             ConsensusMethod
+	MaxDeviation       decimal.Decimal
+	ComputedAt         time.Time
+	Contributions      []ConsensusContribution
+}
+
+func ParseConsensusMethod(method string) (ConsensusMethod, error) {
+	switch ConsensusMethod(method) {
+	case ConsensusMedian, ConsensusWeighted:
+		return ConsensusMethod(method), nil
+	}
+
+	return "", ErrorInvalidConsensus
+}
@@ -1,14 +1,84 @@
 package models
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
 
 var (
-	ErrorCurrencyNotFound = errors.New("Currency Not Found")
+	ErrorCurrencyNotFound      = errors.New("Currency Not Found")
+	ErrorCurrencyAlreadyExists = errors.New("Currency already exists")
+	ErrorInvalidRoundingMode   = errors.New("Invalid rounding mode")
+	ErrorInvalidMinorUnits     = errors.New("Invalid minor units")
 )
 
+const (
+	StandardMinorUnits int32 = 2
+	// MaxMinorUnits bounds the number of decimal places a currency may declare.
+	MaxMinorUnits int32 = 18
+)
+
+type RoundingMode string
+
+const (
+	RoundingHalfUp   RoundingMode = "half-up"
+	RoundingHalfEven RoundingMode = "half-even"
+	RoundingFloor    RoundingMode = "floor"
+	RoundingCeiling  RoundingMode = "ceiling"
+
+	DefaultRoundingMode = RoundingHalfUp
+)
+
+// knownMinorUnits lists currencies whose minor unit differs from the
+// usual two decimal places.
+var knownMinorUnits = map[string]int32{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0,
+	"XOF": 0, "XPF": 0,
+	"BTC": 8,
+}
+
 type Currency struct {
-	ID       int
-	Code     string
-	FullName string
-	Sign     string
+	ID           int
+	Code         string
+	FullName     string
+	Sign         string
+	MinorUnits   int32
+	RoundingMode RoundingMode
+}
+
+// DefaultMinorUnits returns the number of decimal places conventionally used
+// for the currency with the given code.
+func DefaultMinorUnits(code string) int32 {
+	if minorUnits, ok := knownMinorUnits[code]; ok {
+		return minorUnits
+	}
+
+	return StandardMinorUnits
+}
+
+func ParseRoundingMode(mode string) (RoundingMode, error) {
+	switch RoundingMode(mode) {
+	case RoundingHalfUp, RoundingHalfEven, RoundingFloor, RoundingCeiling:
+		return RoundingMode(mode), nil
+	}
+
+	return "", ErrorInvalidRoundingMode
+}
+
+// Round rounds value to the given number of decimal places. Half-up rounds
+// halves away from zero; half-even is banker's rounding.
+func (mode RoundingMode) Round(value decimal.Decimal, places int32) decimal.Decimal {
+	switch mode {
+	case RoundingHalfEven:
+		return value.RoundBank(places)
+	case RoundingFloor:
+		return value.RoundFloor(places)
+	case RoundingCeiling:
+		return value.RoundCeil(places)
+	default:
+		return value.Round(places)
+	}
 }
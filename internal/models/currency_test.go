@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRoundingModeRound(t *testing.T) {
+	tests := []struct {
+		mode   RoundingMode
+		value  string
+		places int32
+		want   string
+	}{
+		{mode: RoundingHalfUp, value: "0.125", places: 2, want: "0.13"},
+		{mode: RoundingHalfUp, value: "-0.125", places: 2, want: "-0.13"},
+		{mode: RoundingHalfEven, value: "0.125", places: 2, want: "0.12"},
+		{mode: RoundingHalfEven, value: "0.135", places: 2, want: "0.14"},
+		{mode: RoundingFloor, value: "0.129", places: 2, want: "0.12"},
+		{mode: RoundingFloor, value: "-0.121", places: 2, want: "-0.13"},
+		{mode: RoundingCeiling, value: "0.121", places: 2, want: "0.13"},
+		{mode: RoundingHalfUp, value: "1234.5", places: 0, want: "1235"},
+		{mode: RoundingHalfUp, value: "0.123456789", places: 8, want: "0.12345679"},
+	}
+
+	for _, test := range tests {
+		got := test.mode.Round(decimal.RequireFromString(test.value), test.places)
+		if !got.Equal(decimal.RequireFromString(test.want)) {
+			t.Errorf("%s.Round(%s, %d) = %s, want %s", test.mode, test.value, test.places, got, test.want)
+		}
+	}
+}
@@ -2,24 +2,177 @@ package models
 
 import (
 	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+// RatePrecision is the number of decimal places kept when a rate has to be
+// derived by division, e.g. when inverting a stored pair.
+const RatePrecision = 16
+
 var (
 	ErrorExchangeRateNotFound      = errors.New("Exchange rate Not Found")
 	ErrorExchangeRateAlreadyExists = errors.New("Exchange rate already exists")
+	ErrorExchangeRateStale         = errors.New("Exchange rate is stale")
+	ErrorInvalidStalenessPolicy    = errors.New("Invalid staleness policy")
+	ErrorInvalidSpread             = errors.New("Invalid spread")
+	ErrorInvalidSide               = errors.New("Invalid side")
+	ErrorInverseRateConflict       = errors.New("Exchange rate contradicts its inverse pair")
+	ErrorInvalidInversePolicy      = errors.New("Invalid inverse policy")
+)
+
+type ConversionMethod string
+
+const (
+	ConversionDirect  ConversionMethod = "direct"
+	ConversionInverse ConversionMethod = "inverse"
+	ConversionCross   ConversionMethod = "cross"
+	ConversionPath    ConversionMethod = "path"
+	// ConversionIdentity converts a currency to itself.
+	ConversionIdentity ConversionMethod = "identity"
+)
+
+// StalenessPolicy decides what a conversion does when it would use a rate
+// that has not been updated for longer than its configured maximum age.
+type StalenessPolicy string
+
+const (
+	// StalenessRefuse fails the conversion.
+	StalenessRefuse StalenessPolicy = "refuse"
+	// StalenessWarn converts anyway and reports the stale rates.
+	StalenessWarn StalenessPolicy = "warn"
+	// StalenessFallback converts through fresh rates only, e.g. a cross rate.
+	StalenessFallback StalenessPolicy = "fallback"
+)
+
+// InversePolicy decides what a write of BASE→TARGET does when TARGET→BASE is
+// stored as well.
+type InversePolicy string
+
+const (
+	// InverseAllow stores both pairs independently.
+	InverseAllow InversePolicy = "allow"
+	// InverseReject refuses writes that are not reciprocal to the inverse pair.
+	InverseReject InversePolicy = "reject"
+	// InverseMaintain sets the inverse pair to the reciprocal of the write.
+	InverseMaintain InversePolicy = "maintain"
 )
 
+// Side is the side of the spread a conversion uses, seen from the client
+// acting on the amount of the base currency.
+type Side string
+
+const (
+	// SideMid converts at the mid rate.
+	SideMid Side = "mid"
+	// SideBuy prices buying the amount, at the ask.
+	SideBuy Side = "buy"
+	// SideSell prices selling the amount, at the bid.
+	SideSell Side = "sell"
+)
+
+// Rate is the mid rate and Spread the distance between ask and bid around it.
+// UpdatedAt is the moment the current value of the rate took effect.
 type CurrencyExchange struct {
 	ID                 int
 	BaseCurrencyCode   string
 	TargetCurrencyCode string
-	Rate               float64
+	Rate               decimal.Decimal
+	Spread             decimal.Decimal
+	UpdatedAt          time.Time
+}
+
+func (exchangerate CurrencyExchange) Bid() decimal.Decimal {
+	return exchangerate.Rate.Sub(exchangerate.Spread.Div(decimal.NewFromInt(2)))
+}
+
+func (exchangerate CurrencyExchange) Ask() decimal.Decimal {
+	return exchangerate.Rate.Add(exchangerate.Spread.Div(decimal.NewFromInt(2)))
+}
+
+// HistoricalRate is a value an exchange rate had from EffectiveFrom until
+// the next entry of the same pair.
+type HistoricalRate struct {
+	ID                 int
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	EffectiveFrom      time.Time
+}
+
+// ConversionStep is a single leg of a conversion. Inverse is set when the
+// leg is derived from the stored TARGET→BASE pair as 1/rate.
+type ConversionStep struct {
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	Inverse            bool
+	ExchangeRate       CurrencyExchange
 }
 
+// ExchangeRequest describes a conversion. An empty RoundingMode falls back
+// to the target currency's default, a zero Date to the current rates and an
+// empty Side to the mid rate. ClientID selects client specific fee rules.
+type ExchangeRequest struct {
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Amount             decimal.Decimal
+	RoundingMode       RoundingMode
+	Date               time.Time
+	Side               Side
+	ClientID           string
+}
+
+// ConvertedAmount is the gross amount at Rate. Fee is what FeeRule charges on
+// top of it and NetAmount what the client receives, or pays on the buy side.
 type GetExchangeCurrencies struct {
 	BaseCurrency    Currency
 	TargetCurrency  Currency
-	Rate            float64
-	Amount          float64
-	ConvertedAmount float64
+	Rate            decimal.Decimal
+	Amount          decimal.Decimal
+	ConvertedAmount decimal.Decimal
+	Fee             decimal.Decimal
+	NetAmount       decimal.Decimal
+	FeeRule         *FeeRule
+	RoundingMode    RoundingMode
+	Side            Side
+	Date            *time.Time
+	Method          ConversionMethod
+	Path            []ConversionStep
+	Warnings        []string
+}
+
+func ParseInversePolicy(policy string) (InversePolicy, error) {
+	switch InversePolicy(policy) {
+	case InverseAllow, InverseReject, InverseMaintain:
+		return InversePolicy(policy), nil
+	}
+
+	return "", ErrorInvalidInversePolicy
+}
+
+func ParseSide(side string) (Side, error) {
+	switch Side(side) {
+	case SideMid, SideBuy, SideSell:
+		return Side(side), nil
+	}
+
+	return "", ErrorInvalidSide
+}
+
+func ParseStalenessPolicy(policy string) (StalenessPolicy, error) {
+	switch StalenessPolicy(policy) {
+	case StalenessRefuse, StalenessWarn, StalenessFallback:
+		return StalenessPolicy(policy), nil
+	}
+
+	return "", ErrorInvalidStalenessPolicy
+}
+
+// BatchExchangeResult is the outcome of one conversion of a batch: either
+// Result or Error is set.
+type BatchExchangeResult struct {
+	Result *GetExchangeCurrencies
+	Error  error
 }
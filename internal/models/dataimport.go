@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CurrencyImportRow is a currency read from line Line of an import file.
+type CurrencyImportRow struct {
+	Line     int
+	Currency Currency
+}
+
+// ExchangeRateImportRow is a rate read from line Line of an import file. A
+// zero EffectiveAt makes the rate effective immediately, a future one
+// schedules it. A valid Spread replaces the spread of the pair, otherwise
+// the pair keeps its spread, or none for a new pair.
+type ExchangeRateImportRow struct {
+	Line               int
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	EffectiveAt        time.Time
+	Spread             decimal.NullDecimal
+}
+
+type ImportRequest struct {
+	Currencies []CurrencyImportRow
+	Rates      []ExchangeRateImportRow
+	DryRun     bool
+}
+
+type ImportRowError struct {
+	File  string
+	Line  int
+	Error string
+}
+
+// ImportReport describes the outcome of an import. Nothing is written
+// unless Imported is set, which requires a valid file and no dry run.
+type ImportReport struct {
+	DryRun     bool
+	Imported   bool
+	Currencies int
+	Rates      int
+	Errors     []ImportRowError
+	// Inconsistencies are reported after the import when checks on write
+	// are enabled.
+	Inconsistencies []RateInconsistency
+}
+
+const (
+	ImportFileCurrencies = "currencies"
+	ImportFileRates      = "rates"
+)
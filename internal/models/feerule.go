@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrorFeeRuleNotFound  = errors.New("Fee rule Not Found")
+	ErrorInvalidFeeRule   = errors.New("Invalid fee rule")
+	ErrorFeeExceedsAmount = errors.New("Fee exceeds the converted amount")
+)
+
+// FeeRule charges a percentage markup and a fixed fee, in the target
+// currency, on conversions it matches. Empty codes and client match any
+// conversion, and the amount of the base currency must lie within
+// [MinAmount, MaxAmount); an invalid MaxAmount leaves the tier unbounded.
+type FeeRule struct {
+	ID                 int
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	ClientID           string
+	MinAmount          decimal.Decimal
+	MaxAmount          decimal.NullDecimal
+	MarkupPercent      decimal.Decimal
+	FixedFee           decimal.Decimal
+}
+
+// Matches reports whether the rule applies to the conversion.
+func (rule FeeRule) Matches(codeBaseCurrency, codeTargetCurrency, clientID string, amount decimal.Decimal) bool {
+	if rule.BaseCurrencyCode != "" && rule.BaseCurrencyCode != codeBaseCurrency {
+		return false
+	}
+	if rule.TargetCurrencyCode != "" && rule.TargetCurrencyCode != codeTargetCurrency {
+		return false
+	}
+	if rule.ClientID != "" && rule.ClientID != clientID {
+		return false
+	}
+	if amount.LessThan(rule.MinAmount) {
+		return false
+	}
+
+	return !rule.MaxAmount.Valid || amount.LessThan(rule.MaxAmount.Decimal)
+}
+
+// Specificity ranks matching rules: a client rule beats a pair rule, which
+// beats a rule on one currency of the pair.
+func (rule FeeRule) Specificity() int {
+	specificity := 0
+	if rule.ClientID != "" {
+		specificity += 4
+	}
+	if rule.BaseCurrencyCode != "" {
+		specificity += 2
+	}
+	if rule.TargetCurrencyCode != "" {
+		specificity++
+	}
+
+	return specificity
+}
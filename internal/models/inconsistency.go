@@ -0,0 +1,14 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// RateInconsistency is a cycle of stored rates, e.g. USD→EUR→GBP→USD, whose
+// product deviates from 1. Cycle starts and ends with the same currency and
+// Steps holds the rate used for every hop, inverted where the pair is only
+// stored the other way round.
+type RateInconsistency struct {
+	Cycle     []string
+	Product   decimal.Decimal
+	Deviation decimal.Decimal
+	Steps     []ConversionStep
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrorProviderNotFound = errors.New("Rate provider Not Found")
+)
+
+// ProviderRun records a single poll of a rate provider. RowsChanged counts
+// the exchange rates that were created or updated as a result, RowsRejected
+// the quotes that were outvoted by other providers.
+type ProviderRun struct {
+	ID           int
+	Provider     string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	RowsFetched  int
+	RowsChanged  int
+	RowsRejected int
+	Errors       []string
+}
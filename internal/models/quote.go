@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrorQuoteNotFound        = errors.New("Quote Not Found")
+	ErrorQuoteExpired         = errors.New("Quote expired")
+	ErrorQuoteAlreadyExecuted = errors.New("Quote already executed")
+)
+
+type QuoteStatus string
+
+const (
+	QuoteOpen     QuoteStatus = "open"
+	QuoteExecuted QuoteStatus = "executed"
+)
+
+// Quote locks the outcome of a conversion until ExpiresAt, so it can be
+// executed at the quoted rate even if the rates change in the meantime.
+type Quote struct {
+	ID                 int
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Amount             decimal.Decimal
+	Rate               decimal.Decimal
+	ConvertedAmount    decimal.Decimal
+	Fee                decimal.Decimal
+	NetAmount          decimal.Decimal
+	Side               Side
+	ClientID           string
+	CreatedAt          time.Time
+	ExpiresAt          time.Time
+	ExecutedAt         *time.Time
+	Status             QuoteStatus
+}
@@ -0,0 +1,18 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// ExchangeRateMatrix holds the rates among a set of currencies: Rates[i][j]
+// converts Currencies[i] to Currencies[j].
+type ExchangeRateMatrix struct {
+	Currencies []string
+	Rates      [][]ExchangeRateMatrixCell
+}
+
+// ExchangeRateMatrixCell is a single rate of the matrix with the method it
+// was derived by. Pairs that cannot be converted have an invalid Rate and an
+// empty Method.
+type ExchangeRateMatrixCell struct {
+	Rate   decimal.NullDecimal
+	Method ConversionMethod
+}
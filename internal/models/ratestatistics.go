@@ -0,0 +1,120 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrorInvalidInterval = errors.New("Interval must be at least one second")
+)
+
+// RateCandle holds the open, high, low and close values of a pair for the
+// rate changes that took effect within [Start, Start+interval).
+type RateCandle struct {
+	Start time.Time
+	Open  decimal.Decimal
+	High  decimal.Decimal
+	Low   decimal.Decimal
+	Close decimal.Decimal
+	Count int
+}
+
+// RateStatistics summarises the rate changes of a pair within a time range.
+// PercentChange compares the last value in the range with the first one.
+type RateStatistics struct {
+	From          time.Time
+	To            time.Time
+	Count         int
+	Min           decimal.Decimal
+	Max           decimal.Decimal
+	Mean          decimal.Decimal
+	StdDev        decimal.Decimal
+	First         decimal.Decimal
+	Last          decimal.Decimal
+	PercentChange decimal.Decimal
+}
+
+// RateSums accumulates rates one at a time, so that the exact mean and
+// population standard deviation of a history are known without keeping it.
+type RateSums struct {
+	count   int64
+	sum     decimal.Decimal
+	squares decimal.Decimal
+}
+
+// Add counts rate in.
+func (sums *RateSums) Add(rate decimal.Decimal) {
+	sums.count++
+	sums.sum = sums.sum.Add(rate)
+	sums.squares = sums.squares.Add(rate.Mul(rate))
+}
+
+// Mean returns the mean of the rates rounded to RatePrecision, zero without
+// rates.
+func (sums RateSums) Mean() decimal.Decimal {
+	if sums.count == 0 {
+		return decimal.Zero
+	}
+
+	return sums.sum.DivRound(decimal.NewFromInt(sums.count), RatePrecision)
+}
+
+// StdDev returns the population standard deviation of the rates rounded to
+// RatePrecision, zero without rates.
+func (sums RateSums) StdDev() decimal.Decimal {
+	if sums.count == 0 {
+		return decimal.Zero
+	}
+
+	// The population variance is (n*Σx² - (Σx)²) / n², which needs no
+	// rounded mean.
+	count := decimal.NewFromInt(sums.count)
+	variance := count.Mul(sums.squares).Sub(sums.sum.Mul(sums.sum)).DivRound(count.Mul(count), RatePrecision+4)
+
+	return sqrt(variance)
+}
+
+// PercentChange returns the change from first to last in percent rounded to
+// RatePrecision, zero when first is zero.
+func PercentChange(first, last decimal.Decimal) decimal.Decimal {
+	if first.IsZero() {
+		return decimal.Zero
+	}
+
+	return last.Sub(first).Mul(decimal.NewFromInt(100)).DivRound(first, RatePrecision)
+}
+
+// sqrt returns the square root of value rounded to RatePrecision, refining
+// the float64 estimate with Newton's method.
+func sqrt(value decimal.Decimal) decimal.Decimal {
+	if !value.IsPositive() {
+		return decimal.Zero
+	}
+
+	two := decimal.NewFromInt(2)
+	root := decimal.NewFromFloat(math.Sqrt(value.InexactFloat64()))
+	if !root.IsPositive() {
+		root = value
+	}
+	for i := 0; i < 10; i++ {
+		next := root.Add(value.DivRound(root, RatePrecision+4)).DivRound(two, RatePrecision+4)
+		if next.Equal(root) {
+			break
+		}
+		root = next
+	}
+
+	return root.Round(RatePrecision)
+}
+
+type ExchangeRateOHLC struct {
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Interval           string
+	Candles            []RateCandle
+	Statistics         RateStatistics
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRateSums(t *testing.T) {
+	tests := []struct {
+		name       string
+		rates      []string
+		wantMean   string
+		wantStdDev string
+	}{
+		{name: "no rates", wantMean: "0", wantStdDev: "0"},
+		{name: "a single rate", rates: []string{"1.0892"}, wantMean: "1.0892", wantStdDev: "0"},
+		{name: "rates not exact in binary", rates: []string{"0.1", "0.2", "0.3"}, wantMean: "0.2", wantStdDev: "0.0816496580927726"},
+		{name: "rates of a pair", rates: []string{"1.0892", "1.0925", "1.0939", "1.0901"}, wantMean: "1.091425", wantStdDev: "0.0018699933154961"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sums RateSums
+			for _, rate := range test.rates {
+				sums.Add(decimal.RequireFromString(rate))
+			}
+
+			if got := sums.Mean(); !got.Equal(decimal.RequireFromString(test.wantMean)) {
+				t.Errorf("Mean() = %s, want %s", got, test.wantMean)
+			}
+			if got := sums.StdDev(); !got.Equal(decimal.RequireFromString(test.wantStdDev)) {
+				t.Errorf("StdDev() = %s, want %s", got, test.wantStdDev)
+			}
+		})
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	tests := []struct {
+		first string
+		last  string
+		want  string
+	}{
+		{first: "1.25", last: "1.5", want: "20"},
+		{first: "1.5", last: "1.25", want: "-16.6666666666666667"},
+		{first: "0", last: "1", want: "0"},
+	}
+
+	for _, test := range tests {
+		got := PercentChange(decimal.RequireFromString(test.first), decimal.RequireFromString(test.last))
+		if !got.Equal(decimal.RequireFromString(test.want)) {
+			t.Errorf("PercentChange(%s, %s) = %s, want %s", test.first, test.last, got, test.want)
+		}
+	}
+}
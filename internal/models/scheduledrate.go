@@ -0,0 +1,32 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrorScheduledRateNotFound = errors.New("Scheduled exchange rate Not Found")
+)
+
+type ScheduledRateStatus string
+
+const (
+	ScheduledRatePending   ScheduledRateStatus = "pending"
+	ScheduledRateActive    ScheduledRateStatus = "active"
+	ScheduledRateCancelled ScheduledRateStatus = "cancelled"
+)
+
+// ScheduledExchangeRate is a rate that is stored ahead of time and becomes
+// the active rate of its pair once EffectiveAt has passed.
+type ScheduledExchangeRate struct {
+	ID                 int
+	BaseCurrencyCode   string
+	TargetCurrencyCode string
+	Rate               decimal.Decimal
+	EffectiveAt        time.Time
+	CreatedAt          time.Time
+	Status             ScheduledRateStatus
+}
@@ -0,0 +1,52 @@
+package cbr
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// windows1251 maps the upper half of the windows-1251 code page to Unicode.
+// 0xC0-0xFF are the contiguous Cyrillic letters А..я.
+var windows1251 = [64]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0xFFFD, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+}
+
+// charsetReader lets encoding/xml read the windows-1251 documents the bank
+// publishes in addition to UTF-8 ones.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8":
+		return input, nil
+	case "windows-1251", "cp1251":
+	default:
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	raw, err := io.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded strings.Builder
+	decoded.Grow(2 * len(raw))
+	for _, b := range raw {
+		switch {
+		case b < 0x80:
+			decoded.WriteByte(b)
+		case b < 0xC0:
+			decoded.WriteRune(windows1251[b-0x80])
+		default:
+			decoded.WriteRune(0x0410 + rune(b-0xC0))
+		}
+	}
+
+	return strings.NewReader(decoded.String()), nil
+}
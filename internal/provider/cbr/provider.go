@@ -0,0 +1,104 @@
+// Package cbr imports the official rates of the Central Bank of Russia from
+// its XML_daily.asp feed, e.g. https://www.cbr.ru/scripts/XML_daily.asp.
+package cbr
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"currencyservice/internal/provider"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	Name = "cbr"
+
+	targetCurrencyCode = "RUB"
+	targetCurrencyName = "Russian Ruble"
+	dateLayout         = "02.01.2006"
+)
+
+// valCurs mirrors the ValCurs document. Value is the price in rubles of
+// Nominal units of the currency and uses a comma as decimal separator.
+type valCurs struct {
+	Date    string `xml:"Date,attr"`
+	Valutes []struct {
+		CharCode string `xml:"CharCode"`
+		Nominal  string `xml:"Nominal"`
+		Name     string `xml:"Name"`
+		Value    string `xml:"Value"`
+	} `xml:"Valute"`
+}
+
+type Provider struct {
+	source string
+}
+
+// NewProvider reads the feed from source, a URL or a local file path.
+func NewProvider(source string) *Provider {
+	return &Provider{source: source}
+}
+
+func (p *Provider) Name() string {
+	return Name
+}
+
+func (p *Provider) FetchRates(ctx context.Context) ([]provider.Rate, error) {
+	feed, err := provider.Open(ctx, p.source)
+	if err != nil {
+		return nil, err
+	}
+	defer feed.Close()
+
+	return Parse(feed)
+}
+
+// Parse reads an XML_daily document into per-unit CODE→RUB rates effective
+// from the start of the document's date (UTC).
+func Parse(feed io.Reader) ([]provider.Rate, error) {
+	decoder := xml.NewDecoder(feed)
+	decoder.CharsetReader = charsetReader
+
+	document := valCurs{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("decoding CBR feed: %w", err)
+	}
+
+	effectiveAt, err := time.Parse(dateLayout, document.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CBR date %q: %w", document.Date, err)
+	}
+
+	rates := make([]provider.Rate, 0, len(document.Valutes))
+	for _, valute := range document.Valutes {
+		value, err := parseNumber(valute.Value)
+		if err != nil || !value.IsPositive() {
+			return nil, fmt.Errorf("invalid CBR value %q for %s", valute.Value, valute.CharCode)
+		}
+
+		nominal, err := parseNumber(valute.Nominal)
+		if err != nil || !nominal.IsPositive() {
+			return nil, fmt.Errorf("invalid CBR nominal %q for %s", valute.Nominal, valute.CharCode)
+		}
+
+		rates = append(rates, provider.Rate{
+			BaseCurrencyCode:   strings.TrimSpace(valute.CharCode),
+			BaseCurrencyName:   strings.TrimSpace(valute.Name),
+			TargetCurrencyCode: targetCurrencyCode,
+			TargetCurrencyName: targetCurrencyName,
+			Rate:               value.DivRound(nominal, models.RatePrecision),
+			EffectiveAt:        effectiveAt,
+		})
+	}
+
+	return rates, nil
+}
+
+func parseNumber(value string) (decimal.Decimal, error) {
+	return decimal.NewFromString(strings.ReplaceAll(strings.TrimSpace(value), ",", "."))
+}
@@ -0,0 +1,76 @@
+package cbr
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFetchRatesFromFile(t *testing.T) {
+	rates, err := NewProvider(filepath.Join("testdata", "XML_daily.xml")).FetchRates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		code string
+		name string
+		rate string
+	}{
+		{code: "USD", name: "Доллар США", rate: "91.6012"},
+		{code: "EUR", name: "Евро", rate: "99.7301"},
+		{code: "JPY", name: "Японских иен", rate: "0.617789"},
+		{code: "HUF", name: "Венгерских форинтов", rate: "0.251203"},
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("FetchRates() returned %d rates, want %d", len(rates), len(want))
+	}
+
+	effectiveAt := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	for i, rate := range rates {
+		if rate.BaseCurrencyCode != want[i].code || rate.TargetCurrencyCode != "RUB" {
+			t.Errorf("rate %d is %s→%s, want %s→RUB", i, rate.BaseCurrencyCode, rate.TargetCurrencyCode, want[i].code)
+		}
+		if rate.BaseCurrencyName != want[i].name {
+			t.Errorf("%s name = %q, want %q", want[i].code, rate.BaseCurrencyName, want[i].name)
+		}
+		if !rate.Rate.Equal(decimal.RequireFromString(want[i].rate)) {
+			t.Errorf("%s rate = %s, want %s per unit", want[i].code, rate.Rate, want[i].rate)
+		}
+		if !rate.EffectiveAt.Equal(effectiveAt) {
+			t.Errorf("%s effective at %s, want %s", want[i].code, rate.EffectiveAt, effectiveAt)
+		}
+	}
+}
+
+func TestParseRejectsMalformedFeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+	}{
+		{name: "truncated document", feed: `<?xml version="1.0" encoding="windows-1251"?><ValCurs Date="15.03.2024"><Valute>`},
+		{name: "not XML", feed: `Service unavailable`},
+		{name: "unsupported charset", feed: `<?xml version="1.0" encoding="koi8-r"?><ValCurs Date="15.03.2024"></ValCurs>`},
+		{name: "invalid date", feed: `<ValCurs Date="2024-03-15"></ValCurs>`},
+		{
+			name: "invalid value",
+			feed: `<ValCurs Date="15.03.2024"><Valute><CharCode>USD</CharCode><Nominal>1</Nominal><Value>n/a</Value></Valute></ValCurs>`,
+		},
+		{
+			name: "zero nominal",
+			feed: `<ValCurs Date="15.03.2024"><Valute><CharCode>JPY</CharCode><Nominal>0</Nominal><Value>61,7789</Value></Valute></ValCurs>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if rates, err := Parse(strings.NewReader(test.feed)); err == nil {
+				t.Errorf("Parse() = %v, want an error", rates)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package ecb imports the euro foreign exchange reference rates published by
+// the European Central Bank, e.g.
+// https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml or the
+// eurofxref-hist.xml file with the full history.
+package ecb
+
+import (
+	"context"
+	"currencyservice/internal/provider"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	Name = "ecb"
+
+	baseCurrencyCode = "EUR"
+	baseCurrencyName = "Euro"
+)
+
+// envelope mirrors the gesmes:Envelope document: a Cube per day, each
+// holding a Cube per currency with the amount of it one euro buys.
+type envelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+type Provider struct {
+	source string
+}
+
+// NewProvider reads the feed from source, a URL or a local file path.
+func NewProvider(source string) *Provider {
+	return &Provider{source: source}
+}
+
+func (p *Provider) Name() string {
+	return Name
+}
+
+func (p *Provider) FetchRates(ctx context.Context) ([]provider.Rate, error) {
+	feed, err := provider.Open(ctx, p.source)
+	if err != nil {
+		return nil, err
+	}
+	defer feed.Close()
+
+	return Parse(feed)
+}
+
+// Parse reads a daily or historical feed. Every rate is EUR based and
+// effective from the start of its reference day (UTC).
+func Parse(feed io.Reader) ([]provider.Rate, error) {
+	document := envelope{}
+	if err := xml.NewDecoder(feed).Decode(&document); err != nil {
+		return nil, fmt.Errorf("decoding ECB feed: %w", err)
+	}
+
+	rates := make([]provider.Rate, 0, 32*len(document.Cube.Days))
+	for _, day := range document.Cube.Days {
+		effectiveAt, err := time.Parse(time.DateOnly, day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECB reference date %q: %w", day.Time, err)
+		}
+
+		for _, cube := range day.Rates {
+			rate, err := decimal.NewFromString(cube.Rate)
+			if err != nil || !rate.IsPositive() {
+				return nil, fmt.Errorf("invalid ECB rate %q for %s on %s", cube.Rate, cube.Currency, day.Time)
+			}
+
+			rates = append(rates, provider.Rate{
+				BaseCurrencyCode:   baseCurrencyCode,
+				BaseCurrencyName:   baseCurrencyName,
+				TargetCurrencyCode: cube.Currency,
+				Rate:               rate,
+				EffectiveAt:        effectiveAt,
+			})
+		}
+	}
+
+	return rates, nil
+}
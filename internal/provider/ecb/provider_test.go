@@ -0,0 +1,83 @@
+package ecb
+
+import (
+	"context"
+	"currencyservice/internal/provider"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// describe renders a rate as "EUR→USD 1.0892 2024-03-15".
+func describe(rate provider.Rate) string {
+	return fmt.Sprintf("%s→%s %s %s", rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, rate.EffectiveAt.Format("2006-01-02 15:04 MST"))
+}
+
+func TestFetchRatesFromFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{
+			file: "eurofxref-daily.xml",
+			want: []string{
+				"EUR→USD 1.0892 2024-03-15 00:00 UTC",
+				"EUR→JPY 162.13 2024-03-15 00:00 UTC",
+				"EUR→GBP 0.85553 2024-03-15 00:00 UTC",
+				"EUR→CHF 0.9616 2024-03-15 00:00 UTC",
+			},
+		},
+		{
+			file: "eurofxref-hist.xml",
+			want: []string{
+				"EUR→USD 1.0892 2024-03-15 00:00 UTC",
+				"EUR→JPY 162.13 2024-03-15 00:00 UTC",
+				"EUR→USD 1.0925 2024-03-14 00:00 UTC",
+				"EUR→JPY 161.62 2024-03-14 00:00 UTC",
+				"EUR→USD 1.0939 2024-03-13 00:00 UTC",
+				"EUR→JPY 161.81 2024-03-13 00:00 UTC",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			rates, err := NewProvider(filepath.Join("testdata", test.file)).FetchRates(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make([]string, 0, len(rates))
+			for _, rate := range rates {
+				got = append(got, describe(rate))
+				if rate.BaseCurrencyName != "Euro" {
+					t.Errorf("%s base name = %q, want Euro", describe(rate), rate.BaseCurrencyName)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+				t.Errorf("FetchRates() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestParseRejectsMalformedFeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+	}{
+		{name: "truncated document", feed: `<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01"><Cube><Cube time="2024-03-15">`},
+		{name: "invalid date", feed: `<Envelope><Cube><Cube time="15.03.2024"><Cube currency="USD" rate="1.0892"/></Cube></Cube></Envelope>`},
+		{name: "invalid rate", feed: `<Envelope><Cube><Cube time="2024-03-15"><Cube currency="USD" rate="N/A"/></Cube></Cube></Envelope>`},
+		{name: "zero rate", feed: `<Envelope><Cube><Cube time="2024-03-15"><Cube currency="USD" rate="0"/></Cube></Cube></Envelope>`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if rates, err := Parse(strings.NewReader(test.feed)); err == nil {
+				t.Errorf("Parse() = %v, want an error", rates)
+			}
+		})
+	}
+}
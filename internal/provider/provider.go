@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rate is an exchange rate reported by a provider. Currency names are
+// optional and only used when the currency has to be created. A zero
+// EffectiveAt means the rate is effective immediately.
+type Rate struct {
+	BaseCurrencyCode   string
+	BaseCurrencyName   string
+	TargetCurrencyCode string
+	TargetCurrencyName string
+	Rate               decimal.Decimal
+	EffectiveAt        time.Time
+}
+
+// RateProvider is an external source of exchange rates, e.g. a central
+// bank feed, that is polled by the rate synchronisation scheduler.
+type RateProvider interface {
+	Name() string
+	FetchRates(ctx context.Context) ([]Rate, error)
+}
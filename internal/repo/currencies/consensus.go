@@ -0,0 +1,155 @@
+package currencies
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// SaveProviderQuote keeps the latest quote of every provider per pair and
+// effective time.
+func (repo *Repo) SaveProviderQuote(ctx context.Context, quote models.ProviderQuote) error {
+	query := `
+		INSERT INTO ProviderQuotes (Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, ObservedAt)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (Provider, BaseCurrencyCode, TargetCurrencyCode, EffectiveAt)
+		DO UPDATE SET Rate = excluded.Rate, ObservedAt = excluded.ObservedAt
+	`
+	_, err := repo.db.ExecContext(ctx, query,
+		quote.Provider,
+		quote.BaseCurrencyCode,
+		quote.TargetCurrencyCode,
+		quote.Rate,
+		effectiveUnix(quote.EffectiveAt),
+		quote.ObservedAt.Unix(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetProviderQuotes returns the quotes of the pair in either direction that
+// take effect at effectiveAt and were observed since the given time.
+func (repo *Repo) GetProviderQuotes(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, effectiveAt, since time.Time) ([]models.ProviderQuote, error) {
+	query := `
+		SELECT Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, ObservedAt FROM ProviderQuotes
+		WHERE ((BaseCurrencyCode = ? AND TargetCurrencyCode = ?) OR (BaseCurrencyCode = ? AND TargetCurrencyCode = ?))
+		AND EffectiveAt = ? AND ObservedAt >= ?
+		ORDER BY Provider
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, codeBaseCurrency, codeTargetCurrency, codeTargetCurrency, codeBaseCurrency, effectiveUnix(effectiveAt), since.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]models.ProviderQuote, 0, 4)
+	defer result.Close()
+
+	for result.Next() {
+		var effectiveAt, observedAt int64
+		quote := models.ProviderQuote{}
+		if err := result.Scan(&quote.Provider, &quote.BaseCurrencyCode, &quote.TargetCurrencyCode, &quote.Rate, &effectiveAt, &observedAt); err != nil {
+			return nil, err
+		}
+		quote.EffectiveAt = effectiveTime(effectiveAt)
+		quote.ObservedAt = time.Unix(observedAt, 0).UTC()
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, result.Err()
+}
+
+// SaveRateConsensus replaces the stored consensus of the pair unless the
+// stored one takes effect later, so backfilling history keeps the latest.
+func (repo *Repo) SaveRateConsensus(ctx context.Context, consensus models.RateConsensus) error {
+	contributions, err := json.Marshal(consensus.Contributions)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO RateConsensus (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, Method, MaxDeviation, ComputedAt, Contributions)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (BaseCurrencyCode, TargetCurrencyCode)
+		DO UPDATE SET Rate = excluded.Rate, EffectiveAt = excluded.EffectiveAt, Method = excluded.Method,
+			MaxDeviation = excluded.MaxDeviation, ComputedAt = excluded.ComputedAt, Contributions = excluded.Contributions
+		WHERE excluded.EffectiveAt >= RateConsensus.EffectiveAt
+	`
+	_, err = repo.db.ExecContext(ctx, query,
+		consensus.BaseCurrencyCode,
+		consensus.TargetCurrencyCode,
+		consensus.Rate,
+		effectiveUnix(consensus.EffectiveAt),
+		consensus.Method,
+		consensus.MaxDeviation,
+		consensus.ComputedAt.Unix(),
+		string(contributions),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GET /admin/consensus/USDRUB
+func (repo *Repo) GetRateConsensus(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.RateConsensus, error) {
+	query := `
+		SELECT BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, Method, MaxDeviation, ComputedAt, Contributions FROM RateConsensus
+		WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ?
+	`
+
+	var (
+		effectiveAt   int64
+		computedAt    int64
+		contributions string
+	)
+	consensus := models.RateConsensus{}
+	err := repo.db.QueryRowContext(ctx, query, codeBaseCurrency, codeTargetCurrency).Scan(
+		&consensus.BaseCurrencyCode,
+		&consensus.TargetCurrencyCode,
+		&consensus.Rate,
+		&effectiveAt,
+		&consensus.Method,
+		&consensus.MaxDeviation,
+		&computedAt,
+		&contributions,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RateConsensus{}, models.ErrorConsensusNotFound
+		}
+		return models.RateConsensus{}, err
+	}
+
+	consensus.EffectiveAt = effectiveTime(effectiveAt)
+	consensus.ComputedAt = time.Unix(computedAt, 0).UTC()
+	if err := json.Unmarshal([]byte(contributions), &consensus.Contributions); err != nil {
+		return models.RateConsensus{}, err
+	}
+
+	return consensus, nil
+}
+
+// effectiveUnix stores the zero time, a rate effective immediately, as 0.
+func effectiveUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
+
+func effectiveTime(unix int64) time.Time {
+	if unix == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(unix, 0).UTC()
+}
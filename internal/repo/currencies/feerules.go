@@ -0,0 +1,125 @@
+package currencies
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"errors"
+)
+
+// POST /feeRules
+func (repo *Repo) AddFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error) {
+	query := `
+		INSERT INTO FeeRules (BaseCurrencyCode, TargetCurrencyCode, ClientID, MinAmount, MaxAmount, MarkupPercent, FixedFee)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING ID
+	`
+	err := repo.db.QueryRowContext(ctx, query,
+		rule.BaseCurrencyCode,
+		rule.TargetCurrencyCode,
+		rule.ClientID,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.MarkupPercent,
+		rule.FixedFee,
+	).Scan(&rule.ID)
+	if err != nil {
+		return models.FeeRule{}, err
+	}
+
+	return rule, nil
+}
+
+// GET /feeRules
+func (repo *Repo) GetFeeRules(ctx context.Context) ([]models.FeeRule, error) {
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, ClientID, MinAmount, MaxAmount, MarkupPercent, FixedFee FROM FeeRules
+		ORDER BY ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	rules := make([]models.FeeRule, 0, 10)
+	for result.Next() {
+		rule := models.FeeRule{}
+		if err := result.Scan(&rule.ID, &rule.BaseCurrencyCode, &rule.TargetCurrencyCode, &rule.ClientID, &rule.MinAmount, &rule.MaxAmount, &rule.MarkupPercent, &rule.FixedFee); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, result.Err()
+}
+
+// GET /feeRules/1
+func (repo *Repo) GetFeeRule(ctx context.Context, id int) (models.FeeRule, error) {
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, ClientID, MinAmount, MaxAmount, MarkupPercent, FixedFee FROM FeeRules
+		WHERE ID = ?
+	`
+
+	rule := models.FeeRule{}
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(&rule.ID, &rule.BaseCurrencyCode, &rule.TargetCurrencyCode, &rule.ClientID, &rule.MinAmount, &rule.MaxAmount, &rule.MarkupPercent, &rule.FixedFee)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.FeeRule{}, models.ErrorFeeRuleNotFound
+		}
+		return models.FeeRule{}, err
+	}
+
+	return rule, nil
+}
+
+// PATCH /feeRules/1
+func (repo *Repo) UpdateFeeRule(ctx context.Context, rule models.FeeRule) error {
+	query := `
+		UPDATE FeeRules SET BaseCurrencyCode = ?, TargetCurrencyCode = ?, ClientID = ?, MinAmount = ?, MaxAmount = ?, MarkupPercent = ?, FixedFee = ?
+		WHERE ID = ?
+	`
+
+	result, err := repo.db.ExecContext(ctx, query,
+		rule.BaseCurrencyCode,
+		rule.TargetCurrencyCode,
+		rule.ClientID,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.MarkupPercent,
+		rule.FixedFee,
+		rule.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if updated, err := result.RowsAffected(); err != nil {
+		return err
+	} else if updated == 0 {
+		return models.ErrorFeeRuleNotFound
+	}
+
+	return nil
+}
+
+// DELETE /feeRules/1
+func (repo *Repo) DeleteFeeRule(ctx context.Context, id int) error {
+	query := `
+		DELETE FROM FeeRules WHERE ID = ?
+	`
+
+	result, err := repo.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if deleted, err := result.RowsAffected(); err != nil {
+		return err
+	} else if deleted == 0 {
+		return models.ErrorFeeRuleNotFound
+	}
+
+	return nil
+}
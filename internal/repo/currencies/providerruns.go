@@ -0,0 +1,71 @@
+package currencies
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"strings"
+	"time"
+)
+
+func (repo *Repo) AddProviderRun(ctx context.Context, run models.ProviderRun) (models.ProviderRun, error) {
+	query := `
+		INSERT INTO ProviderRuns (Provider, StartedAt, FinishedAt, RowsFetched, RowsChanged, RowsRejected, Errors)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING ID
+	`
+	err := repo.db.QueryRowContext(ctx, query,
+		run.Provider,
+		run.StartedAt.Unix(),
+		run.FinishedAt.Unix(),
+		run.RowsFetched,
+		run.RowsChanged,
+		run.RowsRejected,
+		strings.Join(run.Errors, "\n"),
+	).Scan(&run.ID)
+	if err != nil {
+		return models.ProviderRun{}, err
+	}
+
+	return run, nil
+}
+
+// GET /admin/providerRuns
+// GetProviderRuns returns the latest runs first. An empty provider name
+// returns the runs of every provider.
+func (repo *Repo) GetProviderRuns(ctx context.Context, provider string, limit int) ([]models.ProviderRun, error) {
+	query := `
+		SELECT ID, Provider, StartedAt, FinishedAt, RowsFetched, RowsChanged, RowsRejected, Errors FROM ProviderRuns
+		WHERE ? = '' OR Provider = ?
+		ORDER BY ID DESC
+		LIMIT ?
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, provider, provider, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]models.ProviderRun, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		var (
+			startedAt  int64
+			finishedAt int64
+			errors     string
+		)
+		run := models.ProviderRun{}
+		if err := result.Scan(&run.ID, &run.Provider, &startedAt, &finishedAt, &run.RowsFetched, &run.RowsChanged, &run.RowsRejected, &errors); err != nil {
+			return nil, err
+		}
+		run.StartedAt = time.Unix(startedAt, 0).UTC()
+		run.FinishedAt = time.Unix(finishedAt, 0).UTC()
+		run.Errors = make([]string, 0)
+		if errors != "" {
+			run.Errors = strings.Split(errors, "\n")
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, result.Err()
+}
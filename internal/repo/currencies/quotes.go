@@ -0,0 +1,119 @@
+package currencies
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// POST /quotes
+func (repo *Repo) AddQuote(ctx context.Context, quote models.Quote) (models.Quote, error) {
+	quote.CreatedAt = time.Unix(quote.CreatedAt.Unix(), 0).UTC()
+	quote.ExpiresAt = time.Unix(quote.ExpiresAt.Unix(), 0).UTC()
+	quote.Status = models.QuoteOpen
+
+	query := `
+		INSERT INTO Quotes (BaseCurrencyCode, TargetCurrencyCode, Amount, Rate, ConvertedAmount, Fee, NetAmount, Side, ClientID, CreatedAt, ExpiresAt, Status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ID
+	`
+	err := repo.db.QueryRowContext(ctx, query,
+		quote.BaseCurrencyCode,
+		quote.TargetCurrencyCode,
+		quote.Amount,
+		quote.Rate,
+		quote.ConvertedAmount,
+		quote.Fee,
+		quote.NetAmount,
+		quote.Side,
+		quote.ClientID,
+		quote.CreatedAt.Unix(),
+		quote.ExpiresAt.Unix(),
+		quote.Status,
+	).Scan(&quote.ID)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	return quote, nil
+}
+
+// GET /quotes/1
+func (repo *Repo) GetQuote(ctx context.Context, id int) (models.Quote, error) {
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Amount, Rate, ConvertedAmount, Fee, NetAmount, Side, ClientID, CreatedAt, ExpiresAt, ExecutedAt, Status FROM Quotes
+		WHERE ID = ?
+	`
+
+	var (
+		quote                models.Quote
+		createdAt, expiresAt int64
+		executedAt           sql.NullInt64
+	)
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(
+		&quote.ID,
+		&quote.BaseCurrencyCode,
+		&quote.TargetCurrencyCode,
+		&quote.Amount,
+		&quote.Rate,
+		&quote.ConvertedAmount,
+		&quote.Fee,
+		&quote.NetAmount,
+		&quote.Side,
+		&quote.ClientID,
+		&createdAt,
+		&expiresAt,
+		&executedAt,
+		&quote.Status,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Quote{}, models.ErrorQuoteNotFound
+		}
+		return models.Quote{}, err
+	}
+
+	quote.CreatedAt = time.Unix(createdAt, 0).UTC()
+	quote.ExpiresAt = time.Unix(expiresAt, 0).UTC()
+	if executedAt.Valid {
+		executed := time.Unix(executedAt.Int64, 0).UTC()
+		quote.ExecutedAt = &executed
+	}
+
+	return quote, nil
+}
+
+// POST /quotes/1/execute
+// ExecuteQuote marks an open quote that has not expired at the given moment as
+// executed. A quote can only be executed once, even by concurrent requests.
+func (repo *Repo) ExecuteQuote(ctx context.Context, id int, at time.Time) (models.Quote, error) {
+	query := `
+		UPDATE Quotes SET Status = ?, ExecutedAt = ? WHERE ID = ? AND Status = ? AND ExpiresAt >= ?
+	`
+
+	result, err := repo.db.ExecContext(ctx, query, models.QuoteExecuted, at.Unix(), id, models.QuoteOpen, at.Unix())
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	executed, err := result.RowsAffected()
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	quote, err := repo.GetQuote(ctx, id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	if executed == 0 {
+		if quote.Status == models.QuoteExecuted {
+			return models.Quote{}, models.ErrorQuoteAlreadyExecuted
+		}
+		return models.Quote{}, models.ErrorQuoteExpired
+	}
+
+	return quote, nil
+}
@@ -1,9 +1,15 @@
 package currencies
 
 import (
+	"context"
 	"currencyservice/internal/models"
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
 )
 
 type Repo struct {
@@ -14,26 +20,47 @@ func NewRepo(db *sql.DB) *Repo {
 	return &Repo{db: db}
 }
 
+// mapError turns constraint violations into domain errors: a second currency
+// with a stored code or a second row for a stored pair violates a unique
+// index and a rate of an unknown currency a foreign key.
+func mapError(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	switch {
+	case sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique && strings.Contains(sqliteErr.Error(), "Currencies.Code"):
+		return models.ErrorCurrencyAlreadyExists
+	case sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique && strings.Contains(sqliteErr.Error(), "ExchangeRates."):
+		return models.ErrorExchangeRateAlreadyExists
+	case sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey:
+		return models.ErrorCurrencyNotFound
+	}
+
+	return err
+}
+
 // POST /currencies
-func (repo *Repo) AddCurrency(code, fullname, sign string) error {
+func (repo *Repo) AddCurrency(ctx context.Context, currency models.Currency) error {
 	query := `
-		INSERT INTO Currencies (Code, FullName, Sign)
-		VALUES (?, ?, ?)
+		INSERT INTO Currencies (Code, FullName, Sign, MinorUnits, RoundingMode)
+		VALUES (?, ?, ?, ?, ?)
 	`
-	if _, err := repo.db.Exec(query, code, fullname, sign); err != nil {
-		return err
+	if _, err := repo.db.ExecContext(ctx, query, currency.Code, currency.FullName, currency.Sign, currency.MinorUnits, currency.RoundingMode); err != nil {
+		return mapError(err)
 	}
 
 	return nil
 }
 
 // GET /currency/EUR
-// func (repo *Repo) GetCurrencyByID(id int) (models.Currency, error) {
+// func (repo *Repo) GetCurrencyByID(ctx context.Context, id int) (models.Currency, error) {
 // 	query := `
 // 		SELECT ID, Code, FullName, Sign FROM Currencies
 // 		WHERE ID=?
 // 	`
-// 	result, err := repo.db.Query(query, id)
+// 	result, err := repo.db.QueryContext(ctx, query, id)
 
 // 	if err != nil {
 // 		if errors.Is(err, sql.ErrNoRows) {
@@ -50,13 +77,13 @@ func (repo *Repo) AddCurrency(code, fullname, sign string) error {
 // 	return currency, nil
 // }
 
-func (repo *Repo) GetCurrencyByCode(code string) (models.Currency, error) {
+func (repo *Repo) GetCurrencyByCode(ctx context.Context, code string) (models.Currency, error) {
 	query := `
-        SELECT ID, Code, FullName, Sign FROM Currencies
+        SELECT ID, Code, FullName, Sign, MinorUnits, RoundingMode FROM Currencies
         WHERE Code=?
     `
 	currency := models.Currency{}
-	err := repo.db.QueryRow(query, code).Scan(&currency.ID, &currency.Code, &currency.FullName, &currency.Sign)
+	err := repo.db.QueryRowContext(ctx, query, code).Scan(&currency.ID, &currency.Code, &currency.FullName, &currency.Sign, &currency.MinorUnits, &currency.RoundingMode)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return models.Currency{}, models.ErrorCurrencyNotFound
@@ -67,12 +94,12 @@ func (repo *Repo) GetCurrencyByCode(code string) (models.Currency, error) {
 }
 
 // GET /currencies
-func (repo *Repo) GetCurrencies() ([]models.Currency, error) {
+func (repo *Repo) GetCurrencies(ctx context.Context) ([]models.Currency, error) {
 	query := `
-		SELECT ID, Code, FullName, Sign FROM Currencies
+		SELECT ID, Code, FullName, Sign, MinorUnits, RoundingMode FROM Currencies
 	`
 
-	result, err := repo.db.Query(query)
+	result, err := repo.db.QueryContext(ctx, query)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, models.ErrorCurrencyNotFound
@@ -85,7 +112,7 @@ func (repo *Repo) GetCurrencies() ([]models.Currency, error) {
 
 	for result.Next() {
 		currency := models.Currency{}
-		if err := result.Scan(&currency.ID, &currency.Code, &currency.FullName, &currency.Sign); err != nil {
+		if err := result.Scan(&currency.ID, &currency.Code, &currency.FullName, &currency.Sign, &currency.MinorUnits, &currency.RoundingMode); err != nil {
 			return nil, err
 		}
 		currencies = append(currencies, currency)
@@ -95,12 +122,12 @@ func (repo *Repo) GetCurrencies() ([]models.Currency, error) {
 }
 
 // GET /exchangeRates
-func (repo *Repo) GetExchangeRates() ([]models.CurrencyExchange, error) {
+func (repo *Repo) GetExchangeRates(ctx context.Context) ([]models.CurrencyExchange, error) {
 	query := `
-		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate FROM ExchangeRates
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, Spread, UpdatedAt FROM ExchangeRates
 	`
 
-	result, err := repo.db.Query(query)
+	result, err := repo.db.QueryContext(ctx, query)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, models.ErrorCurrencyNotFound
@@ -112,10 +139,12 @@ func (repo *Repo) GetExchangeRates() ([]models.CurrencyExchange, error) {
 	defer result.Close()
 
 	for result.Next() {
+		var updatedAt int64
 		exchangerate := models.CurrencyExchange{}
-		if err := result.Scan(&exchangerate.ID, &exchangerate.BaseCurrencyCode, &exchangerate.TargetCurrencyCode, &exchangerate.Rate); err != nil {
+		if err := result.Scan(&exchangerate.ID, &exchangerate.BaseCurrencyCode, &exchangerate.TargetCurrencyCode, &exchangerate.Rate, &exchangerate.Spread, &updatedAt); err != nil {
 			return nil, err
 		}
+		exchangerate.UpdatedAt = time.Unix(updatedAt, 0).UTC()
 		exchangerates = append(exchangerates, exchangerate)
 	}
 
@@ -123,28 +152,31 @@ func (repo *Repo) GetExchangeRates() ([]models.CurrencyExchange, error) {
 }
 
 // GET /exchangeRate/USDRUB
-func (repo *Repo) GetExchangeRateByCodesPair(codeBaseCurrency, codeTargetCurrency string) (models.CurrencyExchange, error) {
-	baseCurrency, err := repo.GetCurrencyByCode(codeBaseCurrency)
+func (repo *Repo) GetExchangeRateByCodesPair(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.CurrencyExchange, error) {
+	baseCurrency, err := repo.GetCurrencyByCode(ctx, codeBaseCurrency)
 	if err != nil {
 		return models.CurrencyExchange{}, err
 	}
 
-	targetCurrency, err := repo.GetCurrencyByCode(codeTargetCurrency)
+	targetCurrency, err := repo.GetCurrencyByCode(ctx, codeTargetCurrency)
 	if err != nil {
 		return models.CurrencyExchange{}, err
 	}
 
 	query := `
-        SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate FROM ExchangeRates
+        SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, Spread, UpdatedAt FROM ExchangeRates
         WHERE BaseCurrencyCode=? AND TargetCurrencyCode=?
     `
 
+	var updatedAt int64
 	exchangerate := models.CurrencyExchange{}
-	err = repo.db.QueryRow(query, baseCurrency.Code, targetCurrency.Code).Scan(
+	err = repo.db.QueryRowContext(ctx, query, baseCurrency.Code, targetCurrency.Code).Scan(
 		&exchangerate.ID,
 		&exchangerate.BaseCurrencyCode,
 		&exchangerate.TargetCurrencyCode,
 		&exchangerate.Rate,
+		&exchangerate.Spread,
+		&updatedAt,
 	)
 
 	if err != nil {
@@ -153,52 +185,356 @@ func (repo *Repo) GetExchangeRateByCodesPair(codeBaseCurrency, codeTargetCurrenc
 		}
 		return models.CurrencyExchange{}, err
 	}
+	exchangerate.UpdatedAt = time.Unix(updatedAt, 0).UTC()
 
 	return exchangerate, nil
 }
 
 // POST /exchangeRates
-func (repo *Repo) AddExchangeRate(codeBaseCurrency, codeTargetCurrency string, rate float64) error {
-	baseCurrency, err := repo.GetCurrencyByCode(codeBaseCurrency)
+// A valid inverse is written to the reverse pair, if it is stored, in the same transaction.
+func (repo *Repo) AddExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate, spread decimal.Decimal, inverse decimal.NullDecimal) error {
+	baseCurrency, err := repo.GetCurrencyByCode(ctx, codeBaseCurrency)
+	if err != nil {
+		return err
+	}
+
+	targetCurrency, err := repo.GetCurrencyByCode(ctx, codeTargetCurrency)
 	if err != nil {
 		return err
 	}
 
-	targetCurrency, err := repo.GetCurrencyByCode(codeTargetCurrency)
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	now := time.Now()
 	query := `
-		INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate)
-		VALUES (?, ?, ?) 
+		INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate, Spread, UpdatedAt)
+		VALUES (?, ?, ?, ?, ?) 
 	`
-	if _, err := repo.db.Exec(query, baseCurrency.Code, targetCurrency.Code, rate); err != nil {
+	if _, err := tx.ExecContext(ctx, query, baseCurrency.Code, targetCurrency.Code, rate, spread, now.Unix()); err != nil {
+		return mapError(err)
+	}
+
+	if err := addHistory(ctx, tx, baseCurrency.Code, targetCurrency.Code, rate, now); err != nil {
 		return err
 	}
 
-	return nil
+	if err := updateInverse(ctx, tx, baseCurrency.Code, targetCurrency.Code, inverse, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 // PATCH /exchangeRate/USDRUB
-func (repo *Repo) UpdateExchangeRate(codeBaseCurrency, codeTargetCurrency string, newRate float64) error {
-	baseCurrency, err := repo.GetCurrencyByCode(codeBaseCurrency)
+// An invalid spread keeps the spread the pair already has. A valid inverse is
+// written to the reverse pair, if it is stored, in the same transaction.
+func (repo *Repo) UpdateExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, newRate decimal.Decimal, spread, inverse decimal.NullDecimal) error {
+	baseCurrency, err := repo.GetCurrencyByCode(ctx, codeBaseCurrency)
+	if err != nil {
+		return err
+	}
+
+	targetCurrency, err := repo.GetCurrencyByCode(ctx, codeTargetCurrency)
 	if err != nil {
 		return err
 	}
 
-	targetCurrency, err := repo.GetCurrencyByCode(codeTargetCurrency)
+	tx, err := repo.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	now := time.Now()
 	query := `
-		UPDATE ExchangeRates SET Rate = ? WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ?
+		UPDATE ExchangeRates SET Rate = ?, Spread = COALESCE(?, Spread), UpdatedAt = ? WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ?
 	`
 
-	if _, err := repo.db.Exec(query, newRate, baseCurrency.Code, targetCurrency.Code); err != nil {
+	result, err := tx.ExecContext(ctx, query, newRate, spread, now.Unix(), baseCurrency.Code, targetCurrency.Code)
+	if err != nil {
+		return err
+	}
+
+	if updated, err := result.RowsAffected(); err != nil {
+		return err
+	} else if updated == 0 {
+		return models.ErrorExchangeRateNotFound
+	}
+
+	if err := addHistory(ctx, tx, baseCurrency.Code, targetCurrency.Code, newRate, now); err != nil {
+		return err
+	}
+
+	if err := updateInverse(ctx, tx, baseCurrency.Code, targetCurrency.Code, inverse, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TouchExchangeRate marks the current value of the pair as confirmed at the
+// given moment without recording a new history entry.
+func (repo *Repo) TouchExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, at time.Time) error {
+	query := `
+		UPDATE ExchangeRates SET UpdatedAt = ? WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ? AND UpdatedAt < ?
+	`
+	if _, err := repo.db.ExecContext(ctx, query, at.Unix(), codeBaseCurrency, codeTargetCurrency, at.Unix()); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// updateInverse sets the TARGET→BASE pair to a valid inverse, keeping its spread.
+func updateInverse(ctx context.Context, tx *sql.Tx, codeBaseCurrency, codeTargetCurrency string, inverse decimal.NullDecimal, now time.Time) error {
+	if !inverse.Valid {
+		return nil
+	}
+
+	query := `
+		UPDATE ExchangeRates SET Rate = ?, UpdatedAt = ? WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ?
+	`
+	result, err := tx.ExecContext(ctx, query, inverse.Decimal, now.Unix(), codeTargetCurrency, codeBaseCurrency)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return nil
+	}
+
+	return addHistory(ctx, tx, codeTargetCurrency, codeBaseCurrency, inverse.Decimal, now)
+}
+
+func addHistory(ctx context.Context, tx *sql.Tx, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveFrom time.Time) error {
+	query := `
+		INSERT INTO ExchangeRateHistory (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveFrom)
+		VALUES (?, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, query, codeBaseCurrency, codeTargetCurrency, rate, effectiveFrom.Unix()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetLatestRateChange returns when the latest rate change that took effect at
+// or before at did, the zero time when there is none. GetExchangeRatesAt
+// returns the same rates for every moment with the same latest change.
+func (repo *Repo) GetLatestRateChange(ctx context.Context, at time.Time) (time.Time, error) {
+	query := `
+		SELECT MAX(EffectiveFrom) FROM ExchangeRateHistory WHERE EffectiveFrom <= ?
+	`
+
+	var effectiveFrom sql.NullInt64
+	if err := repo.db.QueryRowContext(ctx, query, at.Unix()).Scan(&effectiveFrom); err != nil {
+		return time.Time{}, err
+	}
+
+	if !effectiveFrom.Valid {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(effectiveFrom.Int64, 0).UTC(), nil
+}
+
+// GET /exchange?date=2024-01-31
+// GetExchangeRatesAt returns every pair with the rate that was in effect at the given moment.
+// Spreads are not versioned, so every pair carries its current spread.
+func (repo *Repo) GetExchangeRatesAt(ctx context.Context, at time.Time) ([]models.CurrencyExchange, error) {
+	query := `
+		SELECT e.ID, h.BaseCurrencyCode, h.TargetCurrencyCode, h.Rate, e.Spread, h.EffectiveFrom FROM ExchangeRateHistory h
+		JOIN ExchangeRates e ON e.BaseCurrencyCode = h.BaseCurrencyCode AND e.TargetCurrencyCode = h.TargetCurrencyCode
+		WHERE h.ID = (
+			SELECT latest.ID FROM ExchangeRateHistory latest
+			WHERE latest.BaseCurrencyCode = h.BaseCurrencyCode AND latest.TargetCurrencyCode = h.TargetCurrencyCode
+			AND latest.EffectiveFrom <= ?
+			ORDER BY latest.EffectiveFrom DESC, latest.ID DESC LIMIT 1
+		)
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, at.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	exchangerates := make([]models.CurrencyExchange, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		var effectiveFrom int64
+		exchangerate := models.CurrencyExchange{}
+		if err := result.Scan(&exchangerate.ID, &exchangerate.BaseCurrencyCode, &exchangerate.TargetCurrencyCode, &exchangerate.Rate, &exchangerate.Spread, &effectiveFrom); err != nil {
+			return nil, err
+		}
+		exchangerate.UpdatedAt = time.Unix(effectiveFrom, 0).UTC()
+		exchangerates = append(exchangerates, exchangerate)
+	}
+
+	return exchangerates, result.Err()
+}
+
+// GET /exchangeRate/USDRUB/history?from=&to=
+func (repo *Repo) GetExchangeRateHistory(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) ([]models.HistoricalRate, error) {
+	if _, err := repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveFrom FROM ExchangeRateHistory
+		WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ? AND EffectiveFrom >= ? AND EffectiveFrom <= ?
+		ORDER BY EffectiveFrom, ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, codeBaseCurrency, codeTargetCurrency, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.HistoricalRate, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		var effectiveFrom int64
+		rate := models.HistoricalRate{}
+		if err := result.Scan(&rate.ID, &rate.BaseCurrencyCode, &rate.TargetCurrencyCode, &rate.Rate, &effectiveFrom); err != nil {
+			return nil, err
+		}
+		rate.EffectiveFrom = time.Unix(effectiveFrom, 0).UTC()
+		history = append(history, rate)
+	}
+
+	return history, result.Err()
+}
+
+// GET /exchangeRate/USDRUB/ohlc?interval=1d
+// GetExchangeRateOHLC buckets the history of a pair into intervals aligned to
+// the Unix epoch. Buckets without rate changes are omitted.
+func (repo *Repo) GetExchangeRateOHLC(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time, interval time.Duration) ([]models.RateCandle, error) {
+	if _, err := repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH h AS (
+			SELECT EffectiveFrom - (EffectiveFrom % ?) AS Bucket, EffectiveFrom, ID, Rate, CAST(Rate AS DOUBLE PRECISION) AS Value
+			FROM ExchangeRateHistory
+			WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ? AND EffectiveFrom >= ? AND EffectiveFrom <= ?
+		)
+		SELECT DISTINCT Bucket,
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY EffectiveFrom, ID),
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY Value DESC, EffectiveFrom, ID),
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY Value, EffectiveFrom, ID),
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY EffectiveFrom DESC, ID DESC),
+			COUNT(*) OVER (PARTITION BY Bucket)
+		FROM h
+		ORDER BY Bucket
+	`
+
+	seconds := int64(interval / time.Second)
+	result, err := repo.db.QueryContext(ctx, query, seconds, codeBaseCurrency, codeTargetCurrency, from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]models.RateCandle, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		var bucket int64
+		candle := models.RateCandle{}
+		if err := result.Scan(&bucket, &candle.Open, &candle.High, &candle.Low, &candle.Close, &candle.Count); err != nil {
+			return nil, err
+		}
+		candle.Start = time.Unix(bucket, 0).UTC()
+		candles = append(candles, candle)
+	}
+
+	return candles, result.Err()
+}
+
+// GetExchangeRateStatistics computes min, max, mean, population standard
+// deviation and percent change of a pair's history within the range. Count,
+// extremes and the first and last rates come from one aggregate query over
+// the pair index. SUM and AVG would add the TEXT rates up as floating point,
+// so the mean and deviation are summed exactly from the streamed rates
+// instead. Both queries read the same snapshot within a transaction.
+func (repo *Repo) GetExchangeRateStatistics(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) (models.RateStatistics, error) {
+	if _, err := repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return models.RateStatistics{}, err
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.RateStatistics{}, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH h AS (
+			SELECT EffectiveFrom, ID, Rate, CAST(Rate AS DOUBLE PRECISION) AS Value
+			FROM ExchangeRateHistory
+			WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ? AND EffectiveFrom >= ? AND EffectiveFrom <= ?
+		)
+		SELECT COUNT(*),
+			(SELECT Rate FROM h ORDER BY Value, EffectiveFrom, ID LIMIT 1),
+			(SELECT Rate FROM h ORDER BY Value DESC, EffectiveFrom, ID LIMIT 1),
+			(SELECT Rate FROM h ORDER BY EffectiveFrom, ID LIMIT 1),
+			(SELECT Rate FROM h ORDER BY EffectiveFrom DESC, ID DESC LIMIT 1)
+		FROM h
+	`
+
+	var min, max, first, last decimal.NullDecimal
+	statistics := models.RateStatistics{From: from.UTC(), To: to.UTC()}
+	err = tx.QueryRowContext(ctx, query, codeBaseCurrency, codeTargetCurrency, from.Unix(), to.Unix()).Scan(
+		&statistics.Count, &min, &max, &first, &last,
+	)
+	if err != nil {
+		return models.RateStatistics{}, err
+	}
+
+	if statistics.Count == 0 {
+		return statistics, nil
+	}
+
+	query = `
+		SELECT Rate FROM ExchangeRateHistory
+		WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ? AND EffectiveFrom >= ? AND EffectiveFrom <= ?
+	`
+
+	result, err := tx.QueryContext(ctx, query, codeBaseCurrency, codeTargetCurrency, from.Unix(), to.Unix())
+	if err != nil {
+		return models.RateStatistics{}, err
+	}
+	defer result.Close()
+
+	var sums models.RateSums
+	for result.Next() {
+		var rate decimal.Decimal
+		if err := result.Scan(&rate); err != nil {
+			return models.RateStatistics{}, err
+		}
+		sums.Add(rate)
+	}
+	if err := result.Err(); err != nil {
+		return models.RateStatistics{}, err
+	}
+
+	statistics.Min = min.Decimal
+	statistics.Max = max.Decimal
+	statistics.Mean = sums.Mean()
+	statistics.StdDev = sums.StdDev()
+	statistics.First = first.Decimal
+	statistics.Last = last.Decimal
+	statistics.PercentChange = models.PercentChange(first.Decimal, last.Decimal)
+
+	return statistics, nil
+}
@@ -0,0 +1,212 @@
+package currencies
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// POST /exchangeRates with effectiveAt
+func (repo *Repo) AddScheduledExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveAt time.Time) (models.ScheduledExchangeRate, error) {
+	baseCurrency, err := repo.GetCurrencyByCode(ctx, codeBaseCurrency)
+	if err != nil {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	targetCurrency, err := repo.GetCurrencyByCode(ctx, codeTargetCurrency)
+	if err != nil {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	scheduled := models.ScheduledExchangeRate{
+		BaseCurrencyCode:   baseCurrency.Code,
+		TargetCurrencyCode: targetCurrency.Code,
+		Rate:               rate,
+		EffectiveAt:        time.Unix(effectiveAt.Unix(), 0).UTC(),
+		CreatedAt:          time.Unix(time.Now().Unix(), 0).UTC(),
+		Status:             models.ScheduledRatePending,
+	}
+
+	query := `
+		INSERT INTO ScheduledExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt, Status)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING ID
+	`
+	err = repo.db.QueryRowContext(ctx, query,
+		scheduled.BaseCurrencyCode,
+		scheduled.TargetCurrencyCode,
+		scheduled.Rate,
+		scheduled.EffectiveAt.Unix(),
+		scheduled.CreatedAt.Unix(),
+		scheduled.Status,
+	).Scan(&scheduled.ID)
+	if err != nil {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	return scheduled, nil
+}
+
+// GET /exchangeRates/scheduled
+// Empty codes list the pending rates of every pair.
+func (repo *Repo) GetScheduledExchangeRates(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) ([]models.ScheduledExchangeRate, error) {
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt, Status FROM ScheduledExchangeRates
+		WHERE Status = ? AND (? = '' OR BaseCurrencyCode = ?) AND (? = '' OR TargetCurrencyCode = ?)
+		ORDER BY EffectiveAt, ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, models.ScheduledRatePending, codeBaseCurrency, codeBaseCurrency, codeTargetCurrency, codeTargetCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return scanScheduledExchangeRates(result)
+}
+
+// DELETE /exchangeRates/scheduled/1
+func (repo *Repo) CancelScheduledExchangeRate(ctx context.Context, id int) error {
+	query := `
+		UPDATE ScheduledExchangeRates SET Status = ? WHERE ID = ? AND Status = ?
+	`
+
+	result, err := repo.db.ExecContext(ctx, query, models.ScheduledRateCancelled, id, models.ScheduledRatePending)
+	if err != nil {
+		return err
+	}
+
+	if cancelled, err := result.RowsAffected(); err != nil {
+		return err
+	} else if cancelled == 0 {
+		return models.ErrorScheduledRateNotFound
+	}
+
+	return nil
+}
+
+// ActivateScheduledExchangeRates applies every pending rate whose effective
+// time has passed: the pair is created if needed, the rate is added to its
+// history and the current rate is set to the latest value in effect.
+func (repo *Repo) ActivateScheduledExchangeRates(ctx context.Context, now time.Time) (int, error) {
+	var due int
+	query := `
+		SELECT COUNT(*) FROM ScheduledExchangeRates WHERE Status = ? AND EffectiveAt <= ?
+	`
+	if err := repo.db.QueryRowContext(ctx, query, models.ScheduledRatePending, now.Unix()).Scan(&due); err != nil {
+		return 0, err
+	}
+
+	if due == 0 {
+		return 0, nil
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query = `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt, Status FROM ScheduledExchangeRates
+		WHERE Status = ? AND EffectiveAt <= ?
+		ORDER BY EffectiveAt, ID
+	`
+	result, err := tx.QueryContext(ctx, query, models.ScheduledRatePending, now.Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := scanScheduledExchangeRates(result)
+	result.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, scheduled := range pending {
+		query := `
+			UPDATE ScheduledExchangeRates SET Status = ? WHERE ID = ? AND Status = ?
+		`
+		if _, err := tx.ExecContext(ctx, query, models.ScheduledRateActive, scheduled.ID, models.ScheduledRatePending); err != nil {
+			return 0, err
+		}
+
+		if err := activateRate(ctx, tx, scheduled.BaseCurrencyCode, scheduled.TargetCurrencyCode, scheduled.Rate, scheduled.EffectiveAt, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(pending), nil
+}
+
+// activateRate records a rate that took effect at effectiveFrom, creating the
+// pair if it does not exist yet. Because effectiveFrom may lie in the past the
+// current rate is taken from the latest history entry rather than overwritten.
+func activateRate(ctx context.Context, tx *sql.Tx, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveFrom, now time.Time) error {
+	query := `
+		INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate)
+		VALUES (?, ?, ?)
+		ON CONFLICT (BaseCurrencyCode, TargetCurrencyCode) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, codeBaseCurrency, codeTargetCurrency, rate); err != nil {
+		return mapError(err)
+	}
+
+	if err := addHistory(ctx, tx, codeBaseCurrency, codeTargetCurrency, rate, effectiveFrom); err != nil {
+		return err
+	}
+
+	var (
+		currentRate decimal.Decimal
+		currentFrom int64
+	)
+	query = `
+		SELECT Rate, EffectiveFrom FROM ExchangeRateHistory
+		WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ? AND EffectiveFrom <= ?
+		ORDER BY EffectiveFrom DESC, ID DESC LIMIT 1
+	`
+	if err := tx.QueryRowContext(ctx, query, codeBaseCurrency, codeTargetCurrency, now.Unix()).Scan(&currentRate, &currentFrom); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE ExchangeRates SET Rate = ?, UpdatedAt = ? WHERE BaseCurrencyCode = ? AND TargetCurrencyCode = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, currentRate, currentFrom, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanScheduledExchangeRates(result *sql.Rows) ([]models.ScheduledExchangeRate, error) {
+	scheduledRates := make([]models.ScheduledExchangeRate, 0, 10)
+
+	for result.Next() {
+		var effectiveAt, createdAt int64
+		scheduled := models.ScheduledExchangeRate{}
+		if err := result.Scan(
+			&scheduled.ID,
+			&scheduled.BaseCurrencyCode,
+			&scheduled.TargetCurrencyCode,
+			&scheduled.Rate,
+			&effectiveAt,
+			&createdAt,
+			&scheduled.Status,
+		); err != nil {
+			return nil, err
+		}
+		scheduled.EffectiveAt = time.Unix(effectiveAt, 0).UTC()
+		scheduled.CreatedAt = time.Unix(createdAt, 0).UTC()
+		scheduledRates = append(scheduledRates, scheduled)
+	}
+
+	return scheduledRates, result.Err()
+}
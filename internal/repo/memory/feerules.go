@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"currencyservice/internal/models"
+)
+
+// POST /feeRules
+func (repo *Repo) AddFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	repo.lastFeeRuleID++
+	rule.ID = repo.lastFeeRuleID
+	repo.feeRules = append(repo.feeRules, rule)
+
+	return rule, nil
+}
+
+// GET /feeRules
+func (repo *Repo) GetFeeRules(ctx context.Context) ([]models.FeeRule, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	return append(make([]models.FeeRule, 0, len(repo.feeRules)), repo.feeRules...), nil
+}
+
+// GET /feeRules/1
+func (repo *Repo) GetFeeRule(ctx context.Context, id int) (models.FeeRule, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	index, ok := repo.feeRule(id)
+	if !ok {
+		return models.FeeRule{}, models.ErrorFeeRuleNotFound
+	}
+
+	return repo.feeRules[index], nil
+}
+
+// PATCH /feeRules/1
+func (repo *Repo) UpdateFeeRule(ctx context.Context, rule models.FeeRule) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	index, ok := repo.feeRule(rule.ID)
+	if !ok {
+		return models.ErrorFeeRuleNotFound
+	}
+
+	repo.feeRules[index] = rule
+	return nil
+}
+
+// DELETE /feeRules/1
+func (repo *Repo) DeleteFeeRule(ctx context.Context, id int) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	index, ok := repo.feeRule(id)
+	if !ok {
+		return models.ErrorFeeRuleNotFound
+	}
+
+	repo.feeRules = append(repo.feeRules[:index], repo.feeRules[index+1:]...)
+	return nil
+}
+
+// feeRule returns the index of the rule in repo.feeRules, which is kept in ID
+// order.
+func (repo *Repo) feeRule(id int) (int, bool) {
+	for i, rule := range repo.feeRules {
+		if rule.ID == id {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"sort"
+	"time"
+)
+
+func (repo *Repo) AddProviderRun(ctx context.Context, run models.ProviderRun) (models.ProviderRun, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	run.ID = len(repo.providerRuns) + 1
+	stored := run
+	stored.StartedAt = unixTime(run.StartedAt)
+	stored.FinishedAt = unixTime(run.FinishedAt)
+	stored.Errors = append(make([]string, 0, len(run.Errors)), run.Errors...)
+	repo.providerRuns = append(repo.providerRuns, stored)
+
+	return run, nil
+}
+
+// GET /admin/providerRuns
+// GetProviderRuns returns the latest runs first. An empty provider name
+// returns the runs of every provider.
+func (repo *Repo) GetProviderRuns(ctx context.Context, provider string, limit int) ([]models.ProviderRun, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	runs := make([]models.ProviderRun, 0, 10)
+	for i := len(repo.providerRuns) - 1; i >= 0 && len(runs) < limit; i-- {
+		run := repo.providerRuns[i]
+		if provider != "" && run.Provider != provider {
+			continue
+		}
+		run.Errors = append(make([]string, 0, len(run.Errors)), run.Errors...)
+		runs = append(runs, run)
+	}
+
+	return runs, nil
+}
+
+// SaveProviderQuote keeps the latest quote of every provider per pair and
+// effective time.
+func (repo *Repo) SaveProviderQuote(ctx context.Context, quote models.ProviderQuote) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	quote.ObservedAt = unixTime(quote.ObservedAt)
+	if !quote.EffectiveAt.IsZero() {
+		quote.EffectiveAt = unixTime(quote.EffectiveAt)
+	}
+	key := providerQuoteKey{quote.Provider, quote.BaseCurrencyCode, quote.TargetCurrencyCode, quote.EffectiveAt.Unix()}
+	repo.providerQuotes[key] = quote
+
+	return nil
+}
+
+// GetProviderQuotes returns the quotes of the pair in either direction that
+// take effect at effectiveAt and were observed since the given time.
+func (repo *Repo) GetProviderQuotes(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, effectiveAt, since time.Time) ([]models.ProviderQuote, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	quotes := make([]models.ProviderQuote, 0, 4)
+	for _, quote := range repo.providerQuotes {
+		direct := quote.BaseCurrencyCode == codeBaseCurrency && quote.TargetCurrencyCode == codeTargetCurrency
+		reverse := quote.BaseCurrencyCode == codeTargetCurrency && quote.TargetCurrencyCode == codeBaseCurrency
+		if (direct || reverse) && quote.EffectiveAt.Unix() == effectiveAt.Unix() && quote.ObservedAt.Unix() >= since.Unix() {
+			quotes = append(quotes, quote)
+		}
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		return quotes[i].Provider < quotes[j].Provider
+	})
+
+	return quotes, nil
+}
+
+// SaveRateConsensus replaces the stored consensus of the pair unless the
+// stored one takes effect later, so backfilling history keeps the latest.
+func (repo *Repo) SaveRateConsensus(ctx context.Context, consensus models.RateConsensus) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	key := pairKey{consensus.BaseCurrencyCode, consensus.TargetCurrencyCode}
+	if stored, ok := repo.consensus[key]; ok && stored.EffectiveAt.After(consensus.EffectiveAt) {
+		return nil
+	}
+
+	consensus.EffectiveAt = unixTime(consensus.EffectiveAt)
+	consensus.ComputedAt = unixTime(consensus.ComputedAt)
+	consensus.Contributions = append([]models.ConsensusContribution(nil), consensus.Contributions...)
+	repo.consensus[key] = consensus
+
+	return nil
+}
+
+// GET /admin/consensus/USDRUB
+func (repo *Repo) GetRateConsensus(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.RateConsensus, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	consensus, ok := repo.consensus[pairKey{codeBaseCurrency, codeTargetCurrency}]
+	if !ok {
+		return models.RateConsensus{}, models.ErrorConsensusNotFound
+	}
+
+	consensus.Contributions = append([]models.ConsensusContribution(nil), consensus.Contributions...)
+	return consensus, nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"time"
+)
+
+// POST /quotes
+func (repo *Repo) AddQuote(ctx context.Context, quote models.Quote) (models.Quote, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	quote.ID = len(repo.quotes) + 1
+	quote.CreatedAt = unixTime(quote.CreatedAt)
+	quote.ExpiresAt = unixTime(quote.ExpiresAt)
+	quote.ExecutedAt = nil
+	quote.Status = models.QuoteOpen
+	repo.quotes = append(repo.quotes, quote)
+
+	return quote, nil
+}
+
+// GET /quotes/1
+func (repo *Repo) GetQuote(ctx context.Context, id int) (models.Quote, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	return repo.quote(id)
+}
+
+func (repo *Repo) quote(id int) (models.Quote, error) {
+	if id < 1 || id > len(repo.quotes) {
+		return models.Quote{}, models.ErrorQuoteNotFound
+	}
+
+	quote := repo.quotes[id-1]
+	if quote.ExecutedAt != nil {
+		executed := *quote.ExecutedAt
+		quote.ExecutedAt = &executed
+	}
+
+	return quote, nil
+}
+
+// POST /quotes/1/execute
+// ExecuteQuote marks an open quote that has not expired at the given moment as
+// executed. A quote can only be executed once, even by concurrent requests.
+func (repo *Repo) ExecuteQuote(ctx context.Context, id int, at time.Time) (models.Quote, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	quote, err := repo.quote(id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	if quote.Status == models.QuoteExecuted {
+		return models.Quote{}, models.ErrorQuoteAlreadyExecuted
+	}
+
+	if quote.ExpiresAt.Unix() < at.Unix() {
+		return models.Quote{}, models.ErrorQuoteExpired
+	}
+
+	executed := unixTime(at)
+	repo.quotes[id-1].Status = models.QuoteExecuted
+	repo.quotes[id-1].ExecutedAt = &executed
+
+	return repo.quote(id)
+}
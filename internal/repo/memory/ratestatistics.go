@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// GetExchangeRateOHLC buckets the history of a pair into intervals aligned to
+// the Unix epoch. Buckets without rate changes are omitted.
+func (repo *Repo) GetExchangeRateOHLC(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time, interval time.Duration) ([]models.RateCandle, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	history, err := repo.historyBetween(codeBaseCurrency, codeTargetCurrency, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := int64(interval / time.Second)
+	buckets := make(map[int64][]models.HistoricalRate)
+	for _, rate := range history {
+		bucket := rate.EffectiveFrom.Unix() - rate.EffectiveFrom.Unix()%seconds
+		buckets[bucket] = append(buckets[bucket], rate)
+	}
+
+	starts := make([]int64, 0, len(buckets))
+	for start := range buckets {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	candles := make([]models.RateCandle, 0, len(starts))
+	for _, start := range starts {
+		rates := buckets[start]
+		high, low := extremes(rates)
+		candles = append(candles, models.RateCandle{
+			Start: time.Unix(start, 0).UTC(),
+			Open:  rates[0].Rate,
+			High:  high,
+			Low:   low,
+			Close: rates[len(rates)-1].Rate,
+			Count: len(rates),
+		})
+	}
+
+	return candles, nil
+}
+
+// GetExchangeRateStatistics computes min, max, mean, population standard
+// deviation and percent change of a pair's history within the range in one
+// pass over the stored history, without copying it.
+func (repo *Repo) GetExchangeRateStatistics(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) (models.RateStatistics, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if err := repo.checkCurrencies(codeBaseCurrency, codeTargetCurrency); err != nil {
+		return models.RateStatistics{}, err
+	}
+	if _, ok := repo.exchangeRate(codeBaseCurrency, codeTargetCurrency); !ok {
+		return models.RateStatistics{}, models.ErrorExchangeRateNotFound
+	}
+
+	statistics := models.RateStatistics{From: from.UTC(), To: to.UTC()}
+	var sums models.RateSums
+	var firstAt, lastAt int64
+	for _, rate := range repo.history {
+		if rate.BaseCurrencyCode != codeBaseCurrency || rate.TargetCurrencyCode != codeTargetCurrency {
+			continue
+		}
+		effectiveFrom := rate.EffectiveFrom.Unix()
+		if effectiveFrom < from.Unix() || effectiveFrom > to.Unix() {
+			continue
+		}
+
+		sums.Add(rate.Rate)
+		statistics.Count++
+		if statistics.Count == 1 {
+			statistics.Min, statistics.Max = rate.Rate, rate.Rate
+			statistics.First, statistics.Last = rate.Rate, rate.Rate
+			firstAt, lastAt = effectiveFrom, effectiveFrom
+			continue
+		}
+
+		if rate.Rate.LessThan(statistics.Min) {
+			statistics.Min = rate.Rate
+		}
+		if rate.Rate.GreaterThan(statistics.Max) {
+			statistics.Max = rate.Rate
+		}
+		// Of rates taking effect at the same time, the one stored first
+		// is the first and the one stored last is the last, as in the
+		// history.
+		if effectiveFrom < firstAt {
+			statistics.First, firstAt = rate.Rate, effectiveFrom
+		}
+		if effectiveFrom >= lastAt {
+			statistics.Last, lastAt = rate.Rate, effectiveFrom
+		}
+	}
+
+	if statistics.Count > 0 {
+		statistics.Mean = sums.Mean()
+		statistics.StdDev = sums.StdDev()
+		statistics.PercentChange = models.PercentChange(statistics.First, statistics.Last)
+	}
+
+	return statistics, nil
+}
+
+// extremes returns the highest and lowest rate, the earliest one on ties.
+func extremes(rates []models.HistoricalRate) (decimal.Decimal, decimal.Decimal) {
+	high, low := rates[0].Rate, rates[0].Rate
+	for _, rate := range rates[1:] {
+		if rate.Rate.GreaterThan(high) {
+			high = rate.Rate
+		}
+		if rate.Rate.LessThan(low) {
+			low = rate.Rate
+		}
+	}
+
+	return high, low
+}
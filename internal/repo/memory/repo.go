@@ -0,0 +1,325 @@
+// Package memory keeps every table of the service in memory. It behaves like
+// the SQLite backed currencies.Repo, including the second precision of stored
+// times, but its data is gone when the process exits. Its methods never wait
+// on I/O, so they ignore the contexts they are given.
+package memory
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Repo struct {
+	lock sync.Mutex
+
+	currencies     []models.Currency
+	exchangerates  []models.CurrencyExchange
+	history        []models.HistoricalRate
+	scheduled      []models.ScheduledExchangeRate
+	feeRules       []models.FeeRule
+	lastFeeRuleID  int
+	quotes         []models.Quote
+	providerRuns   []models.ProviderRun
+	providerQuotes map[providerQuoteKey]models.ProviderQuote
+	consensus      map[pairKey]models.RateConsensus
+}
+
+type pairKey struct {
+	base, target string
+}
+
+type providerQuoteKey struct {
+	provider, base, target string
+	effectiveAt            int64
+}
+
+func NewRepo() *Repo {
+	return &Repo{
+		providerQuotes: make(map[providerQuoteKey]models.ProviderQuote),
+		consensus:      make(map[pairKey]models.RateConsensus),
+	}
+}
+
+// unixTime truncates t to the whole seconds the SQLite backend stores.
+func unixTime(t time.Time) time.Time {
+	return time.Unix(t.Unix(), 0).UTC()
+}
+
+func (repo *Repo) AddCurrency(ctx context.Context, currency models.Currency) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if _, err := repo.currency(currency.Code); err == nil {
+		return models.ErrorCurrencyAlreadyExists
+	}
+
+	repo.addCurrency(currency)
+	return nil
+}
+
+func (repo *Repo) addCurrency(currency models.Currency) {
+	currency.ID = len(repo.currencies) + 1
+	repo.currencies = append(repo.currencies, currency)
+}
+
+func (repo *Repo) GetCurrencyByCode(ctx context.Context, code string) (models.Currency, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	return repo.currency(code)
+}
+
+func (repo *Repo) currency(code string) (models.Currency, error) {
+	for _, currency := range repo.currencies {
+		if currency.Code == code {
+			return currency, nil
+		}
+	}
+
+	return models.Currency{}, models.ErrorCurrencyNotFound
+}
+
+func (repo *Repo) GetCurrencies(ctx context.Context) ([]models.Currency, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	return append(make([]models.Currency, 0, len(repo.currencies)), repo.currencies...), nil
+}
+
+func (repo *Repo) GetExchangeRates(ctx context.Context) ([]models.CurrencyExchange, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	return append(make([]models.CurrencyExchange, 0, len(repo.exchangerates)), repo.exchangerates...), nil
+}
+
+func (repo *Repo) GetExchangeRateByCodesPair(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.CurrencyExchange, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if err := repo.checkCurrencies(codeBaseCurrency, codeTargetCurrency); err != nil {
+		return models.CurrencyExchange{}, err
+	}
+
+	index, ok := repo.exchangeRate(codeBaseCurrency, codeTargetCurrency)
+	if !ok {
+		return models.CurrencyExchange{}, models.ErrorExchangeRateNotFound
+	}
+
+	return repo.exchangerates[index], nil
+}
+
+func (repo *Repo) checkCurrencies(codes ...string) error {
+	for _, code := range codes {
+		if _, err := repo.currency(code); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exchangeRate returns the index of the pair in repo.exchangerates.
+func (repo *Repo) exchangeRate(codeBaseCurrency, codeTargetCurrency string) (int, bool) {
+	for i, exchangerate := range repo.exchangerates {
+		if exchangerate.BaseCurrencyCode == codeBaseCurrency && exchangerate.TargetCurrencyCode == codeTargetCurrency {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// AddExchangeRate stores a new pair. A valid inverse is written to the
+// reverse pair, if it is stored.
+func (repo *Repo) AddExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate, spread decimal.Decimal, inverse decimal.NullDecimal) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if err := repo.checkCurrencies(codeBaseCurrency, codeTargetCurrency); err != nil {
+		return err
+	}
+
+	if _, ok := repo.exchangeRate(codeBaseCurrency, codeTargetCurrency); ok {
+		return models.ErrorExchangeRateAlreadyExists
+	}
+
+	now := unixTime(time.Now())
+	repo.exchangerates = append(repo.exchangerates, models.CurrencyExchange{
+		ID:                 len(repo.exchangerates) + 1,
+		BaseCurrencyCode:   codeBaseCurrency,
+		TargetCurrencyCode: codeTargetCurrency,
+		Rate:               rate,
+		Spread:             spread,
+		UpdatedAt:          now,
+	})
+	repo.addHistory(codeBaseCurrency, codeTargetCurrency, rate, now)
+	repo.updateInverse(codeBaseCurrency, codeTargetCurrency, inverse, now)
+
+	return nil
+}
+
+// UpdateExchangeRate sets a new rate. An invalid spread keeps the spread the
+// pair already has and a valid inverse is written to the reverse pair, if it
+// is stored.
+func (repo *Repo) UpdateExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, newRate decimal.Decimal, spread, inverse decimal.NullDecimal) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if err := repo.checkCurrencies(codeBaseCurrency, codeTargetCurrency); err != nil {
+		return err
+	}
+
+	index, ok := repo.exchangeRate(codeBaseCurrency, codeTargetCurrency)
+	if !ok {
+		return models.ErrorExchangeRateNotFound
+	}
+
+	now := unixTime(time.Now())
+	repo.exchangerates[index].Rate = newRate
+	if spread.Valid {
+		repo.exchangerates[index].Spread = spread.Decimal
+	}
+	repo.exchangerates[index].UpdatedAt = now
+	repo.addHistory(codeBaseCurrency, codeTargetCurrency, newRate, now)
+	repo.updateInverse(codeBaseCurrency, codeTargetCurrency, inverse, now)
+
+	return nil
+}
+
+func (repo *Repo) updateInverse(codeBaseCurrency, codeTargetCurrency string, inverse decimal.NullDecimal, now time.Time) {
+	if !inverse.Valid {
+		return
+	}
+
+	index, ok := repo.exchangeRate(codeTargetCurrency, codeBaseCurrency)
+	if !ok {
+		return
+	}
+
+	repo.exchangerates[index].Rate = inverse.Decimal
+	repo.exchangerates[index].UpdatedAt = now
+	repo.addHistory(codeTargetCurrency, codeBaseCurrency, inverse.Decimal, now)
+}
+
+// TouchExchangeRate marks the current value of the pair as confirmed at the
+// given moment without recording a new history entry.
+func (repo *Repo) TouchExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, at time.Time) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	index, ok := repo.exchangeRate(codeBaseCurrency, codeTargetCurrency)
+	if ok && repo.exchangerates[index].UpdatedAt.Before(unixTime(at)) {
+		repo.exchangerates[index].UpdatedAt = unixTime(at)
+	}
+
+	return nil
+}
+
+func (repo *Repo) addHistory(codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveFrom time.Time) {
+	repo.history = append(repo.history, models.HistoricalRate{
+		ID:                 len(repo.history) + 1,
+		BaseCurrencyCode:   codeBaseCurrency,
+		TargetCurrencyCode: codeTargetCurrency,
+		Rate:               rate,
+		EffectiveFrom:      unixTime(effectiveFrom),
+	})
+}
+
+// latestHistory returns the latest history entry of the pair in effect at the
+// given moment.
+func (repo *Repo) latestHistory(codeBaseCurrency, codeTargetCurrency string, at time.Time) (models.HistoricalRate, bool) {
+	var (
+		latest models.HistoricalRate
+		found  bool
+	)
+	for _, rate := range repo.history {
+		if rate.BaseCurrencyCode != codeBaseCurrency || rate.TargetCurrencyCode != codeTargetCurrency || rate.EffectiveFrom.Unix() > at.Unix() {
+			continue
+		}
+		if !found || !rate.EffectiveFrom.Before(latest.EffectiveFrom) {
+			latest, found = rate, true
+		}
+	}
+
+	return latest, found
+}
+
+// GetExchangeRatesAt returns every pair with the rate that was in effect at
+// the given moment. Spreads are not versioned, so every pair carries its
+// current spread.
+func (repo *Repo) GetExchangeRatesAt(ctx context.Context, at time.Time) ([]models.CurrencyExchange, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	exchangerates := make([]models.CurrencyExchange, 0, len(repo.exchangerates))
+	for _, exchangerate := range repo.exchangerates {
+		rate, ok := repo.latestHistory(exchangerate.BaseCurrencyCode, exchangerate.TargetCurrencyCode, at)
+		if !ok {
+			continue
+		}
+
+		exchangerate.Rate = rate.Rate
+		exchangerate.UpdatedAt = rate.EffectiveFrom
+		exchangerates = append(exchangerates, exchangerate)
+	}
+
+	return exchangerates, nil
+}
+
+// GetLatestRateChange returns when the latest rate change that took effect at
+// or before at did, the zero time when there is none.
+func (repo *Repo) GetLatestRateChange(ctx context.Context, at time.Time) (time.Time, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	var latest time.Time
+	for _, rate := range repo.history {
+		if rate.EffectiveFrom.Unix() <= at.Unix() && rate.EffectiveFrom.After(latest) {
+			latest = rate.EffectiveFrom
+		}
+	}
+
+	return latest, nil
+}
+
+// historyBetween returns the history of the pair between from and to in the
+// order the rates took effect.
+func (repo *Repo) historyBetween(codeBaseCurrency, codeTargetCurrency string, from, to time.Time) ([]models.HistoricalRate, error) {
+	if err := repo.checkCurrencies(codeBaseCurrency, codeTargetCurrency); err != nil {
+		return nil, err
+	}
+
+	if _, ok := repo.exchangeRate(codeBaseCurrency, codeTargetCurrency); !ok {
+		return nil, models.ErrorExchangeRateNotFound
+	}
+
+	history := make([]models.HistoricalRate, 0, 10)
+	for _, rate := range repo.history {
+		if rate.BaseCurrencyCode != codeBaseCurrency || rate.TargetCurrencyCode != codeTargetCurrency {
+			continue
+		}
+		if rate.EffectiveFrom.Unix() < from.Unix() || rate.EffectiveFrom.Unix() > to.Unix() {
+			continue
+		}
+		history = append(history, rate)
+	}
+
+	sort.SliceStable(history, func(i, j int) bool {
+		return history[i].EffectiveFrom.Before(history[j].EffectiveFrom)
+	})
+
+	return history, nil
+}
+
+func (repo *Repo) GetExchangeRateHistory(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) ([]models.HistoricalRate, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	return repo.historyBetween(codeBaseCurrency, codeTargetCurrency, from, to)
+}
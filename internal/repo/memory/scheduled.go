@@ -0,0 +1,170 @@
+package memory
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// POST /exchangeRates with effectiveAt
+func (repo *Repo) AddScheduledExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveAt time.Time) (models.ScheduledExchangeRate, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if err := repo.checkCurrencies(codeBaseCurrency, codeTargetCurrency); err != nil {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	return repo.addScheduled(codeBaseCurrency, codeTargetCurrency, rate, effectiveAt, time.Now()), nil
+}
+
+func (repo *Repo) addScheduled(codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveAt, createdAt time.Time) models.ScheduledExchangeRate {
+	scheduled := models.ScheduledExchangeRate{
+		ID:                 len(repo.scheduled) + 1,
+		BaseCurrencyCode:   codeBaseCurrency,
+		TargetCurrencyCode: codeTargetCurrency,
+		Rate:               rate,
+		EffectiveAt:        unixTime(effectiveAt),
+		CreatedAt:          unixTime(createdAt),
+		Status:             models.ScheduledRatePending,
+	}
+	repo.scheduled = append(repo.scheduled, scheduled)
+
+	return scheduled
+}
+
+// GET /exchangeRates/scheduled
+// Empty codes list the pending rates of every pair.
+func (repo *Repo) GetScheduledExchangeRates(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) ([]models.ScheduledExchangeRate, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	scheduledRates := make([]models.ScheduledExchangeRate, 0, 10)
+	for _, scheduled := range repo.scheduled {
+		if scheduled.Status != models.ScheduledRatePending {
+			continue
+		}
+		if codeBaseCurrency != "" && scheduled.BaseCurrencyCode != codeBaseCurrency {
+			continue
+		}
+		if codeTargetCurrency != "" && scheduled.TargetCurrencyCode != codeTargetCurrency {
+			continue
+		}
+		scheduledRates = append(scheduledRates, scheduled)
+	}
+
+	sortScheduled(scheduledRates)
+	return scheduledRates, nil
+}
+
+// DELETE /exchangeRates/scheduled/1
+func (repo *Repo) CancelScheduledExchangeRate(ctx context.Context, id int) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	if id < 1 || id > len(repo.scheduled) || repo.scheduled[id-1].Status != models.ScheduledRatePending {
+		return models.ErrorScheduledRateNotFound
+	}
+
+	repo.scheduled[id-1].Status = models.ScheduledRateCancelled
+	return nil
+}
+
+// ActivateScheduledExchangeRates applies every pending rate whose effective
+// time has passed: the pair is created if needed, the rate is added to its
+// history and the current rate is set to the latest value in effect.
+func (repo *Repo) ActivateScheduledExchangeRates(ctx context.Context, now time.Time) (int, error) {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	pending := make([]models.ScheduledExchangeRate, 0)
+	for _, scheduled := range repo.scheduled {
+		if scheduled.Status == models.ScheduledRatePending && scheduled.EffectiveAt.Unix() <= now.Unix() {
+			pending = append(pending, scheduled)
+		}
+	}
+	sortScheduled(pending)
+
+	for _, scheduled := range pending {
+		repo.scheduled[scheduled.ID-1].Status = models.ScheduledRateActive
+		repo.activateRate(scheduled.BaseCurrencyCode, scheduled.TargetCurrencyCode, scheduled.Rate, scheduled.EffectiveAt, now)
+	}
+
+	return len(pending), nil
+}
+
+// activateRate records a rate that took effect at effectiveFrom, creating the
+// pair if it does not exist yet. Because effectiveFrom may lie in the past the
+// current rate is taken from the latest history entry rather than overwritten.
+func (repo *Repo) activateRate(codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveFrom, now time.Time) {
+	index, ok := repo.exchangeRate(codeBaseCurrency, codeTargetCurrency)
+	if !ok {
+		index = len(repo.exchangerates)
+		repo.exchangerates = append(repo.exchangerates, models.CurrencyExchange{
+			ID:                 index + 1,
+			BaseCurrencyCode:   codeBaseCurrency,
+			TargetCurrencyCode: codeTargetCurrency,
+			Rate:               rate,
+		})
+	}
+
+	repo.addHistory(codeBaseCurrency, codeTargetCurrency, rate, effectiveFrom)
+
+	if current, ok := repo.latestHistory(codeBaseCurrency, codeTargetCurrency, now); ok {
+		repo.exchangerates[index].Rate = current.Rate
+		repo.exchangerates[index].UpdatedAt = current.EffectiveFrom
+	}
+}
+
+func sortScheduled(scheduledRates []models.ScheduledExchangeRate) {
+	sort.SliceStable(scheduledRates, func(i, j int) bool {
+		return scheduledRates[i].EffectiveAt.Before(scheduledRates[j].EffectiveAt)
+	})
+}
+
+// POST /import
+// Import upserts currencies by code and applies rates like provider updates,
+// future ones are scheduled. The whole import happens under one lock, so it
+// is never seen half applied.
+func (repo *Repo) Import(ctx context.Context, currencies []models.Currency, rates []models.ExchangeRateImportRow) error {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+
+	for _, currency := range currencies {
+		updated := false
+		for i := range repo.currencies {
+			if repo.currencies[i].Code == currency.Code {
+				currency.ID = repo.currencies[i].ID
+				repo.currencies[i] = currency
+				updated = true
+			}
+		}
+		if !updated {
+			repo.addCurrency(currency)
+		}
+	}
+
+	now := time.Now()
+	for _, rate := range rates {
+		if rate.EffectiveAt.After(now) {
+			repo.addScheduled(rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, rate.EffectiveAt, now)
+			continue
+		}
+
+		effectiveFrom := rate.EffectiveAt
+		if effectiveFrom.IsZero() {
+			effectiveFrom = now
+		}
+
+		repo.activateRate(rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, effectiveFrom, now)
+
+		if index, ok := repo.exchangeRate(rate.BaseCurrencyCode, rate.TargetCurrencyCode); ok && rate.Spread.Valid {
+			repo.exchangerates[index].Spread = rate.Spread.Decimal
+		}
+	}
+
+	return nil
+}
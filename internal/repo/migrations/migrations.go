@@ -0,0 +1,257 @@
+// Package migrations evolves the database schema through numbered migrations.
+// Every migration runs in its own transaction together with the schema_version
+// row recording it, so a failed migration leaves the schema at the previous
+// version.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrorNoMigrationToRevert = errors.New("No applied migration to revert")
+	ErrorSchemaBehind        = errors.New("Database schema has pending migrations")
+)
+
+// Migration moves the schema from Version-1 to Version and Down back again.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx) error
+	Down    func(ctx context.Context, tx *sql.Tx) error
+}
+
+// Status describes a known migration and whether the database has it.
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	// numbered placeholders ($1) are used instead of question marks.
+	numbered bool
+	// disableForeignKeys turns enforcement off while a migration runs, as
+	// rebuilding a referenced or referencing table requires in SQLite.
+	disableForeignKeys bool
+}
+
+// NewSQLite returns a migrator for the schema of the SQLite backend.
+func NewSQLite(db *sql.DB) *Migrator {
+	return &Migrator{db: db, migrations: sqlite, disableForeignKeys: true}
+}
+
+// NewPostgres returns a migrator for the schema of the Postgres backend.
+func NewPostgres(db *sql.DB) *Migrator {
+	return &Migrator{db: db, migrations: postgres, numbered: true}
+}
+
+// Up applies every pending migration in order and returns the applied ones.
+// Cancelling ctx rolls back the migration that is running.
+func (migrator *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make([]Migration, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		if migration.Version <= version {
+			continue
+		}
+
+		query := `
+			INSERT INTO schema_version (Version, Name, AppliedAt) VALUES (?, ?, ?)
+		`
+		err := migrator.inTx(ctx, migration.Up, migrator.bind(query), migration.Version, migration.Name, time.Now().Unix())
+		if err != nil {
+			return applied, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Down reverts the latest applied migration.
+func (migrator *Migrator) Down(ctx context.Context) (Migration, error) {
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return Migration{}, err
+	}
+
+	if version == 0 {
+		return Migration{}, ErrorNoMigrationToRevert
+	}
+
+	migration := migrator.migrations[version-1]
+	query := `
+		DELETE FROM schema_version WHERE Version = ?
+	`
+	if err := migrator.inTx(ctx, migration.Down, migrator.bind(query), migration.Version); err != nil {
+		return Migration{}, fmt.Errorf("migration %d %s: %w", migration.Version, migration.Name, err)
+	}
+
+	return migration, nil
+}
+
+// Status lists every known migration in order.
+func (migrator *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, Status{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// Version returns the latest applied migration, 0 for an empty database.
+// A database migrated by a newer build is reported as an error since its
+// schema is unknown to this one.
+func (migrator *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := migrator.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for applied := range applied {
+		if applied > len(migrator.migrations) {
+			return 0, fmt.Errorf("database schema version %d is newer than the latest known migration %d", applied, len(migrator.migrations))
+		}
+		version = max(version, applied)
+	}
+
+	return version, nil
+}
+
+// Check reports ErrorSchemaBehind unless every migration is applied.
+func (migrator *Migrator) Check(ctx context.Context) error {
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version < len(migrator.migrations) {
+		return fmt.Errorf("%w: at version %d of %d", ErrorSchemaBehind, version, len(migrator.migrations))
+	}
+
+	return nil
+}
+
+func (migrator *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	schemaVersion := `CREATE TABLE IF NOT EXISTS schema_version (
+    Version INTEGER PRIMARY KEY,
+    Name VARCHAR(100) NOT NULL,
+    AppliedAt BIGINT NOT NULL
+	);`
+
+	if _, err := migrator.db.ExecContext(ctx, schemaVersion); err != nil {
+		return nil, err
+	}
+
+	result, err := migrator.db.QueryContext(ctx, `SELECT Version, AppliedAt FROM schema_version`)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	applied := make(map[int]time.Time)
+	for result.Next() {
+		var version int
+		var appliedAt int64
+		if err := result.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = time.Unix(appliedAt, 0).UTC()
+	}
+
+	return applied, result.Err()
+}
+
+// inTx runs step and the schema_version query in one transaction.
+func (migrator *Migrator) inTx(ctx context.Context, step func(ctx context.Context, tx *sql.Tx) error, query string, args ...interface{}) error {
+	conn, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// The pragma has no effect inside a transaction, so it is set on the
+	// connection the transaction runs on. It is restored even when ctx is
+	// cancelled, as the connection goes back to the pool.
+	if migrator.disableForeignKeys {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.WithoutCancel(ctx), "PRAGMA foreign_keys = ON")
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := step(ctx, tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// bind rewrites question mark placeholders for databases that number them.
+func (migrator *Migrator) bind(query string) string {
+	if !migrator.numbered {
+		return query
+	}
+
+	var bound strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			bound.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		bound.WriteRune(r)
+	}
+
+	return bound.String()
+}
+
+// execAll returns a migration step running the statements in order.
+func execAll(statements ...string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,138 @@
+package migrations
+
+var postgres = []Migration{
+	{Version: 1, Name: "baseline", Up: postgresBaselineUp, Down: postgresBaselineDown},
+	{Version: 2, Name: "dated provider quotes", Up: postgresDatedProviderQuotesUp, Down: postgresDatedProviderQuotesDown},
+}
+
+// postgresBaselineUp creates the schema of the Postgres backend. Unlike the
+// SQLite schema, amounts and rates are NUMERIC, currency codes are unique and
+// every rate references its currencies.
+var postgresBaselineUp = execAll(postgresSchema)
+
+const postgresSchema = `CREATE TABLE IF NOT EXISTS Currencies (
+    ID SERIAL PRIMARY KEY,
+    Code VARCHAR(10) NOT NULL CONSTRAINT CurrenciesCodeKey UNIQUE,
+    FullName VARCHAR(100) NOT NULL,
+    Sign VARCHAR(10) NOT NULL,
+    MinorUnits INTEGER NOT NULL DEFAULT 2,
+    RoundingMode VARCHAR(16) NOT NULL DEFAULT 'half-up'
+	);
+	CREATE TABLE IF NOT EXISTS ExchangeRates (
+    ID SERIAL PRIMARY KEY,
+    BaseCurrencyCode VARCHAR(10) NOT NULL REFERENCES Currencies (Code) ON UPDATE CASCADE ON DELETE CASCADE,
+    TargetCurrencyCode VARCHAR(10) NOT NULL REFERENCES Currencies (Code) ON UPDATE CASCADE ON DELETE CASCADE,
+    Rate NUMERIC NOT NULL,
+    UpdatedAt TIMESTAMPTZ NOT NULL DEFAULT now(),
+    Spread NUMERIC NOT NULL DEFAULT 0,
+    CONSTRAINT ExchangeRatesPairKey UNIQUE (BaseCurrencyCode, TargetCurrencyCode)
+	);
+	CREATE TABLE IF NOT EXISTS ExchangeRateHistory (
+    ID SERIAL PRIMARY KEY,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate NUMERIC NOT NULL,
+    EffectiveFrom TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (BaseCurrencyCode, TargetCurrencyCode) REFERENCES ExchangeRates (BaseCurrencyCode, TargetCurrencyCode) ON UPDATE CASCADE ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS ExchangeRateHistoryPairIndex
+    ON ExchangeRateHistory (BaseCurrencyCode, TargetCurrencyCode, EffectiveFrom);
+	CREATE TABLE IF NOT EXISTS ScheduledExchangeRates (
+    ID SERIAL PRIMARY KEY,
+    BaseCurrencyCode VARCHAR(10) NOT NULL REFERENCES Currencies (Code) ON UPDATE CASCADE ON DELETE CASCADE,
+    TargetCurrencyCode VARCHAR(10) NOT NULL REFERENCES Currencies (Code) ON UPDATE CASCADE ON DELETE CASCADE,
+    Rate NUMERIC NOT NULL,
+    EffectiveAt TIMESTAMPTZ NOT NULL,
+    CreatedAt TIMESTAMPTZ NOT NULL,
+    Status VARCHAR(16) NOT NULL DEFAULT 'pending'
+	);
+	CREATE INDEX IF NOT EXISTS ScheduledExchangeRatesStatusIndex
+    ON ScheduledExchangeRates (Status, EffectiveAt);
+	CREATE TABLE IF NOT EXISTS ProviderRuns (
+    ID SERIAL PRIMARY KEY,
+    Provider VARCHAR(64) NOT NULL,
+    StartedAt TIMESTAMPTZ NOT NULL,
+    FinishedAt TIMESTAMPTZ NOT NULL,
+    RowsFetched INTEGER NOT NULL,
+    RowsChanged INTEGER NOT NULL,
+    RowsRejected INTEGER NOT NULL DEFAULT 0,
+    Errors TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE IF NOT EXISTS ProviderQuotes (
+    Provider VARCHAR(64) NOT NULL,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate NUMERIC NOT NULL,
+    ObservedAt TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (Provider, BaseCurrencyCode, TargetCurrencyCode)
+	);
+	CREATE TABLE IF NOT EXISTS RateConsensus (
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate NUMERIC NOT NULL,
+    Method VARCHAR(16) NOT NULL,
+    MaxDeviation NUMERIC NOT NULL,
+    ComputedAt TIMESTAMPTZ NOT NULL,
+    Contributions JSONB NOT NULL,
+    PRIMARY KEY (BaseCurrencyCode, TargetCurrencyCode)
+	);
+	CREATE TABLE IF NOT EXISTS FeeRules (
+    ID SERIAL PRIMARY KEY,
+    BaseCurrencyCode VARCHAR(10) NOT NULL DEFAULT '',
+    TargetCurrencyCode VARCHAR(10) NOT NULL DEFAULT '',
+    ClientID VARCHAR(64) NOT NULL DEFAULT '',
+    MinAmount NUMERIC NOT NULL DEFAULT 0,
+    MaxAmount NUMERIC,
+    MarkupPercent NUMERIC NOT NULL DEFAULT 0,
+    FixedFee NUMERIC NOT NULL DEFAULT 0
+	);
+	CREATE TABLE IF NOT EXISTS Quotes (
+    ID SERIAL PRIMARY KEY,
+    BaseCurrencyCode VARCHAR(10) NOT NULL REFERENCES Currencies (Code) ON UPDATE CASCADE,
+    TargetCurrencyCode VARCHAR(10) NOT NULL REFERENCES Currencies (Code) ON UPDATE CASCADE,
+    Amount NUMERIC NOT NULL,
+    Rate NUMERIC NOT NULL,
+    ConvertedAmount NUMERIC NOT NULL,
+    Fee NUMERIC NOT NULL,
+    NetAmount NUMERIC NOT NULL,
+    Side VARCHAR(8) NOT NULL,
+    ClientID VARCHAR(64) NOT NULL DEFAULT '',
+    CreatedAt TIMESTAMPTZ NOT NULL,
+    ExpiresAt TIMESTAMPTZ NOT NULL,
+    ExecutedAt TIMESTAMPTZ,
+    Status VARCHAR(16) NOT NULL DEFAULT 'open'
+	);`
+
+var postgresBaselineDown = execAll(
+	`DROP TABLE IF EXISTS Quotes`,
+	`DROP TABLE IF EXISTS FeeRules`,
+	`DROP TABLE IF EXISTS RateConsensus`,
+	`DROP TABLE IF EXISTS ProviderQuotes`,
+	`DROP TABLE IF EXISTS ProviderRuns`,
+	`DROP TABLE IF EXISTS ScheduledExchangeRates`,
+	`DROP TABLE IF EXISTS ExchangeRateHistory`,
+	`DROP TABLE IF EXISTS ExchangeRates`,
+	`DROP TABLE IF EXISTS Currencies`,
+)
+
+// postgresDatedProviderQuotesUp keeps a quote per provider, pair and effective
+// time, the zero time standing for quotes effective immediately, so that
+// rates for different dates are not compared with each other. Existing
+// quotes are taken as immediate.
+var postgresDatedProviderQuotesUp = execAll(
+	`ALTER TABLE ProviderQuotes ADD COLUMN EffectiveAt TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00'`,
+	`ALTER TABLE ProviderQuotes DROP CONSTRAINT ProviderQuotes_pkey`,
+	`ALTER TABLE ProviderQuotes ADD PRIMARY KEY (Provider, BaseCurrencyCode, TargetCurrencyCode, EffectiveAt)`,
+	`ALTER TABLE RateConsensus ADD COLUMN EffectiveAt TIMESTAMPTZ NOT NULL DEFAULT '0001-01-01 00:00:00+00'`,
+)
+
+// postgresDatedProviderQuotesDown keeps the quote of every provider and pair
+// that takes effect last.
+var postgresDatedProviderQuotesDown = execAll(
+	`DELETE FROM ProviderQuotes AS quote WHERE EffectiveAt < (SELECT MAX(EffectiveAt) FROM ProviderQuotes AS latest
+    WHERE latest.Provider = quote.Provider AND latest.BaseCurrencyCode = quote.BaseCurrencyCode AND latest.TargetCurrencyCode = quote.TargetCurrencyCode)`,
+	`ALTER TABLE ProviderQuotes DROP CONSTRAINT ProviderQuotes_pkey`,
+	`ALTER TABLE ProviderQuotes DROP COLUMN EffectiveAt`,
+	`ALTER TABLE ProviderQuotes ADD PRIMARY KEY (Provider, BaseCurrencyCode, TargetCurrencyCode)`,
+	`ALTER TABLE RateConsensus DROP COLUMN EffectiveAt`,
+)
@@ -0,0 +1,369 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var sqlite = []Migration{
+	{Version: 1, Name: "baseline", Up: sqliteBaselineUp, Down: sqliteBaselineDown},
+	{Version: 2, Name: "currency code keys", Up: sqliteCurrencyCodeKeysUp, Down: sqliteCurrencyCodeKeysDown},
+	{Version: 3, Name: "dated provider quotes", Up: sqliteDatedProviderQuotesUp, Down: sqliteDatedProviderQuotesDown},
+}
+
+// sqliteBaselineUp creates the schema as it was before migrations were kept.
+// Databases created by earlier versions of the service already have some of
+// the tables, so they are brought up to date instead.
+func sqliteBaselineUp(ctx context.Context, tx *sql.Tx) error {
+	currencies := `CREATE TABLE IF NOT EXISTS Currencies (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    Code VARCHAR(10) NOT NULL,
+    FullName VARCHAR(100) NOT NULL,
+    Sign VARCHAR(10) NOT NULL,
+    MinorUnits INTEGER NOT NULL DEFAULT 2,
+    RoundingMode VARCHAR(16) NOT NULL DEFAULT 'half-up'
+	);`
+
+	if _, err := tx.ExecContext(ctx, currencies); err != nil {
+		return err
+	}
+
+	if err := addColumnIfNotExists(ctx, tx, "Currencies", "MinorUnits", "INTEGER NOT NULL DEFAULT 2"); err != nil {
+		return err
+	}
+
+	if err := addColumnIfNotExists(ctx, tx, "Currencies", "RoundingMode", "VARCHAR(16) NOT NULL DEFAULT 'half-up'"); err != nil {
+		return err
+	}
+
+	currencyexchange := `CREATE TABLE IF NOT EXISTS ExchangeRates (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    UpdatedAt INTEGER NOT NULL DEFAULT 0,
+    Spread TEXT NOT NULL DEFAULT '0',
+    FOREIGN KEY (BaseCurrencyCode) REFERENCES Currencies(ID) ON DELETE CASCADE,
+    FOREIGN KEY (TargetCurrencyCode) REFERENCES Currencies(ID) ON DELETE CASCADE
+	);`
+
+	if _, err := tx.ExecContext(ctx, currencyexchange); err != nil {
+		return err
+	}
+
+	if err := addColumnIfNotExists(ctx, tx, "ExchangeRates", "UpdatedAt", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	if err := addColumnIfNotExists(ctx, tx, "ExchangeRates", "Spread", "TEXT NOT NULL DEFAULT '0'"); err != nil {
+		return err
+	}
+
+	history := `CREATE TABLE IF NOT EXISTS ExchangeRateHistory (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    EffectiveFrom INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS ExchangeRateHistoryPairIndex
+    ON ExchangeRateHistory (BaseCurrencyCode, TargetCurrencyCode, EffectiveFrom);`
+
+	if _, err := tx.ExecContext(ctx, history); err != nil {
+		return err
+	}
+
+	scheduled := `CREATE TABLE IF NOT EXISTS ScheduledExchangeRates (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    EffectiveAt INTEGER NOT NULL,
+    CreatedAt INTEGER NOT NULL,
+    Status VARCHAR(16) NOT NULL DEFAULT 'pending'
+	);
+	CREATE INDEX IF NOT EXISTS ScheduledExchangeRatesStatusIndex
+    ON ScheduledExchangeRates (Status, EffectiveAt);`
+
+	if _, err := tx.ExecContext(ctx, scheduled); err != nil {
+		return err
+	}
+
+	providerRuns := `CREATE TABLE IF NOT EXISTS ProviderRuns (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    Provider VARCHAR(64) NOT NULL,
+    StartedAt INTEGER NOT NULL,
+    FinishedAt INTEGER NOT NULL,
+    RowsFetched INTEGER NOT NULL,
+    RowsChanged INTEGER NOT NULL,
+    RowsRejected INTEGER NOT NULL DEFAULT 0,
+    Errors TEXT NOT NULL DEFAULT ''
+	);`
+
+	if _, err := tx.ExecContext(ctx, providerRuns); err != nil {
+		return err
+	}
+
+	if err := addColumnIfNotExists(ctx, tx, "ProviderRuns", "RowsRejected", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	consensus := `CREATE TABLE IF NOT EXISTS ProviderQuotes (
+    Provider VARCHAR(64) NOT NULL,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    ObservedAt INTEGER NOT NULL,
+    PRIMARY KEY (Provider, BaseCurrencyCode, TargetCurrencyCode)
+	);
+	CREATE TABLE IF NOT EXISTS RateConsensus (
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    Method VARCHAR(16) NOT NULL,
+    MaxDeviation TEXT NOT NULL,
+    ComputedAt INTEGER NOT NULL,
+    Contributions TEXT NOT NULL,
+    PRIMARY KEY (BaseCurrencyCode, TargetCurrencyCode)
+	);`
+
+	if _, err := tx.ExecContext(ctx, consensus); err != nil {
+		return err
+	}
+
+	feeRules := `CREATE TABLE IF NOT EXISTS FeeRules (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL DEFAULT '',
+    TargetCurrencyCode VARCHAR(10) NOT NULL DEFAULT '',
+    ClientID VARCHAR(64) NOT NULL DEFAULT '',
+    MinAmount TEXT NOT NULL DEFAULT '0',
+    MaxAmount TEXT,
+    MarkupPercent TEXT NOT NULL DEFAULT '0',
+    FixedFee TEXT NOT NULL DEFAULT '0'
+	);`
+
+	if _, err := tx.ExecContext(ctx, feeRules); err != nil {
+		return err
+	}
+
+	quotes := `CREATE TABLE IF NOT EXISTS Quotes (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Amount TEXT NOT NULL,
+    Rate TEXT NOT NULL,
+    ConvertedAmount TEXT NOT NULL,
+    Fee TEXT NOT NULL,
+    NetAmount TEXT NOT NULL,
+    Side VARCHAR(8) NOT NULL,
+    ClientID VARCHAR(64) NOT NULL DEFAULT '',
+    CreatedAt INTEGER NOT NULL,
+    ExpiresAt INTEGER NOT NULL,
+    ExecutedAt INTEGER,
+    Status VARCHAR(16) NOT NULL DEFAULT 'open'
+	);`
+
+	if _, err := tx.ExecContext(ctx, quotes); err != nil {
+		return err
+	}
+
+	// Rates stored before history was kept start their history now.
+	seedHistory := `INSERT INTO ExchangeRateHistory (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveFrom)
+    SELECT e.BaseCurrencyCode, e.TargetCurrencyCode, e.Rate, ? FROM ExchangeRates e
+    WHERE NOT EXISTS (
+        SELECT 1 FROM ExchangeRateHistory h
+        WHERE h.BaseCurrencyCode = e.BaseCurrencyCode AND h.TargetCurrencyCode = e.TargetCurrencyCode
+    );`
+
+	if _, err := tx.ExecContext(ctx, seedHistory, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	// Rates stored before update times were tracked take them from their history.
+	seedUpdatedAt := `UPDATE ExchangeRates SET UpdatedAt = COALESCE((
+        SELECT MAX(h.EffectiveFrom) FROM ExchangeRateHistory h
+        WHERE h.BaseCurrencyCode = ExchangeRates.BaseCurrencyCode AND h.TargetCurrencyCode = ExchangeRates.TargetCurrencyCode
+        AND h.EffectiveFrom <= ?
+    ), 0)
+    WHERE UpdatedAt = 0;`
+
+	if _, err := tx.ExecContext(ctx, seedUpdatedAt, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+var sqliteBaselineDown = execAll(
+	`DROP TABLE IF EXISTS Quotes`,
+	`DROP TABLE IF EXISTS FeeRules`,
+	`DROP TABLE IF EXISTS RateConsensus`,
+	`DROP TABLE IF EXISTS ProviderQuotes`,
+	`DROP TABLE IF EXISTS ProviderRuns`,
+	`DROP TABLE IF EXISTS ScheduledExchangeRates`,
+	`DROP TABLE IF EXISTS ExchangeRateHistory`,
+	`DROP TABLE IF EXISTS ExchangeRates`,
+	`DROP TABLE IF EXISTS Currencies`,
+)
+
+// sqliteCurrencyCodeKeysUp makes currency codes and pairs unique and points
+// the foreign keys of ExchangeRates at Currencies(Code); the baseline
+// referenced Currencies(ID) from code columns. Duplicates are dropped first,
+// keeping the oldest row, which is the one lookups by code used to return,
+// and so are rates of currencies that do not exist, together with the history
+// and scheduled rates of those pairs. Every dropped row is kept in a
+// <table>_migration_dropped table for review. SQLite cannot alter foreign
+// keys, so ExchangeRates is rebuilt. The rebuild also replaces the
+// DECIMAL(6, 4) Rate column of databases created before rates were exact
+// decimals: its NUMERIC affinity stores rates as floats, and the baseline
+// leaves existing tables as they are, so until this migration runs such
+// databases keep rounding rates on write.
+var sqliteCurrencyCodeKeysUp = execAll(
+	`CREATE TABLE Currencies_migration_dropped AS
+    SELECT * FROM Currencies WHERE ID NOT IN (SELECT MIN(ID) FROM Currencies GROUP BY Code)`,
+	`DELETE FROM Currencies WHERE ID IN (SELECT ID FROM Currencies_migration_dropped)`,
+	`CREATE UNIQUE INDEX CurrenciesCodeIndex ON Currencies (Code)`,
+	`CREATE TABLE ExchangeRates_migration_dropped AS
+    SELECT * FROM ExchangeRates
+    WHERE ID NOT IN (SELECT MIN(ID) FROM ExchangeRates GROUP BY BaseCurrencyCode, TargetCurrencyCode)
+    OR BaseCurrencyCode NOT IN (SELECT Code FROM Currencies) OR TargetCurrencyCode NOT IN (SELECT Code FROM Currencies)`,
+	`DELETE FROM ExchangeRates WHERE ID IN (SELECT ID FROM ExchangeRates_migration_dropped)`,
+	`CREATE TABLE ExchangeRateHistory_migration_dropped AS
+    SELECT * FROM ExchangeRateHistory h
+    WHERE NOT EXISTS (
+        SELECT 1 FROM ExchangeRates e
+        WHERE e.BaseCurrencyCode = h.BaseCurrencyCode AND e.TargetCurrencyCode = h.TargetCurrencyCode
+    )`,
+	`DELETE FROM ExchangeRateHistory WHERE ID IN (SELECT ID FROM ExchangeRateHistory_migration_dropped)`,
+	`CREATE TABLE ScheduledExchangeRates_migration_dropped AS
+    SELECT * FROM ScheduledExchangeRates s
+    WHERE NOT EXISTS (
+        SELECT 1 FROM ExchangeRates e
+        WHERE e.BaseCurrencyCode = s.BaseCurrencyCode AND e.TargetCurrencyCode = s.TargetCurrencyCode
+    )`,
+	`DELETE FROM ScheduledExchangeRates WHERE ID IN (SELECT ID FROM ScheduledExchangeRates_migration_dropped)`,
+	`CREATE TABLE ExchangeRatesMigrated (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    UpdatedAt INTEGER NOT NULL DEFAULT 0,
+    Spread TEXT NOT NULL DEFAULT '0',
+    FOREIGN KEY (BaseCurrencyCode) REFERENCES Currencies(Code) ON DELETE CASCADE,
+    FOREIGN KEY (TargetCurrencyCode) REFERENCES Currencies(Code) ON DELETE CASCADE
+	)`,
+	`INSERT INTO ExchangeRatesMigrated (ID, BaseCurrencyCode, TargetCurrencyCode, Rate, UpdatedAt, Spread)
+    SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, UpdatedAt, Spread FROM ExchangeRates`,
+	`DROP TABLE ExchangeRates`,
+	`ALTER TABLE ExchangeRatesMigrated RENAME TO ExchangeRates`,
+	`CREATE UNIQUE INDEX ExchangeRatesPairIndex ON ExchangeRates (BaseCurrencyCode, TargetCurrencyCode)`,
+)
+
+// sqliteCurrencyCodeKeysDown drops the unique indexes and puts the dropped
+// rows back. Dropped rates get new IDs, since the rebuilt table may have
+// handed theirs out since. The baseline foreign keys referenced Currencies(ID)
+// from code columns, which no rate satisfies now that repo.NewDB enforces
+// foreign keys, so ExchangeRates is rebuilt without foreign keys, as the
+// service that created the baseline effectively ran it.
+var sqliteCurrencyCodeKeysDown = execAll(
+	`CREATE TABLE ExchangeRatesMigrated (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    UpdatedAt INTEGER NOT NULL DEFAULT 0,
+    Spread TEXT NOT NULL DEFAULT '0'
+	)`,
+	`INSERT INTO ExchangeRatesMigrated (ID, BaseCurrencyCode, TargetCurrencyCode, Rate, UpdatedAt, Spread)
+    SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, UpdatedAt, Spread FROM ExchangeRates`,
+	`INSERT INTO ExchangeRatesMigrated (BaseCurrencyCode, TargetCurrencyCode, Rate, UpdatedAt, Spread)
+    SELECT BaseCurrencyCode, TargetCurrencyCode, Rate, UpdatedAt, Spread FROM ExchangeRates_migration_dropped ORDER BY ID`,
+	`DROP TABLE ExchangeRates`,
+	`ALTER TABLE ExchangeRatesMigrated RENAME TO ExchangeRates`,
+	`DROP INDEX CurrenciesCodeIndex`,
+	`INSERT INTO Currencies SELECT * FROM Currencies_migration_dropped`,
+	`INSERT INTO ExchangeRateHistory SELECT * FROM ExchangeRateHistory_migration_dropped`,
+	`INSERT INTO ScheduledExchangeRates SELECT * FROM ScheduledExchangeRates_migration_dropped`,
+	`DROP TABLE Currencies_migration_dropped`,
+	`DROP TABLE ExchangeRates_migration_dropped`,
+	`DROP TABLE ExchangeRateHistory_migration_dropped`,
+	`DROP TABLE ScheduledExchangeRates_migration_dropped`,
+)
+
+// sqliteDatedProviderQuotesUp keeps a quote per provider, pair and effective
+// time, 0 standing for quotes effective immediately, so that rates for
+// different dates are not compared with each other. The primary key changes,
+// so ProviderQuotes is rebuilt; existing quotes are taken as immediate.
+var sqliteDatedProviderQuotesUp = execAll(
+	`CREATE TABLE ProviderQuotesMigrated (
+    Provider VARCHAR(64) NOT NULL,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    EffectiveAt INTEGER NOT NULL DEFAULT 0,
+    ObservedAt INTEGER NOT NULL,
+    PRIMARY KEY (Provider, BaseCurrencyCode, TargetCurrencyCode, EffectiveAt)
+	)`,
+	`INSERT INTO ProviderQuotesMigrated (Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, ObservedAt)
+    SELECT Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, ObservedAt FROM ProviderQuotes`,
+	`DROP TABLE ProviderQuotes`,
+	`ALTER TABLE ProviderQuotesMigrated RENAME TO ProviderQuotes`,
+	`ALTER TABLE RateConsensus ADD COLUMN EffectiveAt INTEGER NOT NULL DEFAULT 0`,
+)
+
+// sqliteDatedProviderQuotesDown keeps the quote of every provider and pair
+// that takes effect last.
+var sqliteDatedProviderQuotesDown = execAll(
+	`CREATE TABLE ProviderQuotesMigrated (
+    Provider VARCHAR(64) NOT NULL,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    ObservedAt INTEGER NOT NULL,
+    PRIMARY KEY (Provider, BaseCurrencyCode, TargetCurrencyCode)
+	)`,
+	`INSERT INTO ProviderQuotesMigrated (Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, ObservedAt)
+    SELECT Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, ObservedAt FROM ProviderQuotes AS quote
+    WHERE EffectiveAt = (SELECT MAX(EffectiveAt) FROM ProviderQuotes AS latest
+        WHERE latest.Provider = quote.Provider AND latest.BaseCurrencyCode = quote.BaseCurrencyCode AND latest.TargetCurrencyCode = quote.TargetCurrencyCode)`,
+	`DROP TABLE ProviderQuotes`,
+	`ALTER TABLE ProviderQuotesMigrated RENAME TO ProviderQuotes`,
+	`ALTER TABLE RateConsensus DROP COLUMN EffectiveAt`,
+)
+
+// addColumnIfNotExists brings tables created by earlier versions of the
+// service up to date, since CREATE TABLE IF NOT EXISTS leaves them as is.
+func addColumnIfNotExists(ctx context.Context, tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      int
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return err
+		}
+		if strings.EqualFold(name, column) {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
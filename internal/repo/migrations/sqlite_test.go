@@ -0,0 +1,283 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// legacySQLiteSchema is the schema created by versions of the service from
+// before migrations were kept: codes are not unique, the foreign keys of
+// ExchangeRates reference Currencies(ID) and rates are DECIMAL(6, 4).
+// ScheduledExchangeRates is as the baseline creates it.
+const legacySQLiteSchema = `CREATE TABLE Currencies (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    Code VARCHAR(10) NOT NULL,
+    FullName VARCHAR(100) NOT NULL,
+    Sign VARCHAR(10) NOT NULL
+	);
+	CREATE TABLE ExchangeRates (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate DECIMAL(6, 4) NOT NULL,
+    FOREIGN KEY (BaseCurrencyCode) REFERENCES Currencies(ID) ON DELETE CASCADE,
+    FOREIGN KEY (TargetCurrencyCode) REFERENCES Currencies(ID) ON DELETE CASCADE
+	);
+	CREATE TABLE ScheduledExchangeRates (
+    ID INTEGER PRIMARY KEY AUTOINCREMENT,
+    BaseCurrencyCode VARCHAR(10) NOT NULL,
+    TargetCurrencyCode VARCHAR(10) NOT NULL,
+    Rate TEXT NOT NULL,
+    EffectiveAt INTEGER NOT NULL,
+    CreatedAt INTEGER NOT NULL,
+    Status VARCHAR(16) NOT NULL DEFAULT 'pending'
+	);`
+
+// openLegacySQLite returns a database created and filled by the legacy
+// schema, with duplicate codes and pairs and a rate and a scheduled rate of an
+// unknown currency.
+// Earlier versions of the service did not enforce foreign keys, which is how
+// such rows came to exist; the database is reopened with enforcement on, as
+// the service opens it now.
+func openLegacySQLite(t *testing.T) *sql.DB {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "sqlite-database.db")
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer legacy.Close()
+
+	statements := []string{
+		legacySQLiteSchema,
+		`INSERT INTO Currencies (ID, Code, FullName, Sign) VALUES
+    (1, 'USD', 'US Dollar', '$'),
+    (2, 'EUR', 'Euro', '€'),
+    (3, 'USD', 'Duplicate Dollar', '$')`,
+		`INSERT INTO ExchangeRates (ID, BaseCurrencyCode, TargetCurrencyCode, Rate) VALUES
+    (1, 'USD', 'EUR', 0.9123),
+    (2, 'USD', 'EUR', 0.5),
+    (3, 'USD', 'GBP', 0.79),
+    (4, 'EUR', 'USD', 1.0961)`,
+		`INSERT INTO ScheduledExchangeRates (ID, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt) VALUES
+    (1, 'USD', 'EUR', '0.92', 1, 1),
+    (2, 'USD', 'GBP', '0.8', 1, 1)`,
+	}
+	for _, statement := range statements {
+		if _, err := legacy.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// queryStrings returns the single string column of every row of query.
+func queryStrings(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, value)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return values
+}
+
+func TestSQLiteMigratesLegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openLegacySQLite(t)
+	migrator := NewSQLite(db)
+
+	if err := migrator.Check(ctx); !errors.Is(err, ErrorSchemaBehind) {
+		t.Fatalf("Check() on a legacy database error = %v, want %v", err, ErrorSchemaBehind)
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(sqlite) {
+		t.Fatalf("Up() applied %d migrations, want %d", len(applied), len(sqlite))
+	}
+
+	currencies := queryStrings(t, db, `SELECT ID || ' ' || Code || ' ' || MinorUnits || ' ' || RoundingMode FROM Currencies ORDER BY ID`)
+	if want := []string{"1 USD 2 half-up", "2 EUR 2 half-up"}; !slices.Equal(currencies, want) {
+		t.Errorf("Currencies = %v, want the oldest of each code %v", currencies, want)
+	}
+
+	rates := queryStrings(t, db, `SELECT ID || ' ' || BaseCurrencyCode || TargetCurrencyCode || ' ' || Rate || ' ' || typeof(Rate) || ' ' || Spread FROM ExchangeRates ORDER BY ID`)
+	if want := []string{"1 USDEUR 0.9123 text 0", "4 EURUSD 1.0961 text 0"}; !slices.Equal(rates, want) {
+		t.Errorf("ExchangeRates = %v, want the oldest of each pair without orphans %v", rates, want)
+	}
+
+	pairs := queryStrings(t, db, `SELECT DISTINCT BaseCurrencyCode || TargetCurrencyCode FROM ExchangeRateHistory ORDER BY 1`)
+	if want := []string{"EURUSD", "USDEUR"}; !slices.Equal(pairs, want) {
+		t.Errorf("ExchangeRateHistory has pairs %v, want %v without orphans", pairs, want)
+	}
+	scheduled := queryStrings(t, db, `SELECT ID || ' ' || BaseCurrencyCode || TargetCurrencyCode FROM ScheduledExchangeRates ORDER BY ID`)
+	if want := []string{"1 USDEUR"}; !slices.Equal(scheduled, want) {
+		t.Errorf("ScheduledExchangeRates = %v, want %v without orphans", scheduled, want)
+	}
+
+	dropped := []struct {
+		query string
+		want  []string
+	}{
+		{query: `SELECT ID || ' ' || Code || ' ' || FullName FROM Currencies_migration_dropped ORDER BY ID`, want: []string{"3 USD Duplicate Dollar"}},
+		{query: `SELECT ID || ' ' || BaseCurrencyCode || TargetCurrencyCode || ' ' || Rate FROM ExchangeRates_migration_dropped ORDER BY ID`, want: []string{"2 USDEUR 0.5", "3 USDGBP 0.79"}},
+		{query: `SELECT BaseCurrencyCode || TargetCurrencyCode || ' ' || Rate FROM ExchangeRateHistory_migration_dropped ORDER BY ID`, want: []string{"USDGBP 0.79"}},
+		{query: `SELECT ID || ' ' || BaseCurrencyCode || TargetCurrencyCode FROM ScheduledExchangeRates_migration_dropped ORDER BY ID`, want: []string{"2 USDGBP"}},
+	}
+	for _, test := range dropped {
+		if got := queryStrings(t, db, test.query); !slices.Equal(got, test.want) {
+			t.Errorf("%s = %v, want %v", test.query, got, test.want)
+		}
+	}
+
+	if _, err := db.Exec(`INSERT INTO Currencies (Code, FullName, Sign) VALUES ('EUR', 'Euro', '€')`); err == nil {
+		t.Error("a second EUR was stored after the migration")
+	}
+	if _, err := db.Exec(`INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate) VALUES ('USD', 'EUR', '0.9')`); err == nil {
+		t.Error("a second USDEUR was stored after the migration")
+	}
+	if _, err := db.Exec(`INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate) VALUES ('USD', 'GBP', '0.79')`); err == nil {
+		t.Error("a rate of an unknown currency was stored after the migration")
+	}
+
+	if _, err := db.Exec(`INSERT INTO Currencies (Code, FullName, Sign) VALUES ('GBP', 'Pound', '£')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate) VALUES ('USD', 'GBP', '0.7912345678901234567')`); err != nil {
+		t.Fatal(err)
+	}
+	var rate string
+	if err := db.QueryRow(`SELECT Rate FROM ExchangeRates WHERE BaseCurrencyCode = 'USD' AND TargetCurrencyCode = 'GBP'`).Scan(&rate); err != nil {
+		t.Fatal(err)
+	}
+	if rate != "0.7912345678901234567" {
+		t.Errorf("USDGBP rate = %s, want it stored exactly", rate)
+	}
+
+	if err := migrator.Check(ctx); err != nil {
+		t.Errorf("Check() after Up() error = %v", err)
+	}
+}
+
+func TestSQLiteMigrationsDownAndStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openLegacySQLite(t)
+	migrator := NewSQLite(db)
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for version := len(sqlite); version > 1; version-- {
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reverted.Version != version {
+			t.Errorf("Down() reverted migration %d, want %d", reverted.Version, version)
+		}
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.Applied != (status.Version == 1) {
+			t.Errorf("Status() after Down() = %+v, want only the baseline applied", statuses)
+		}
+	}
+	if version, err := migrator.Version(ctx); err != nil || version != 1 {
+		t.Errorf("Version() after Down() = %d, %v, want 1", version, err)
+	}
+
+	currencies := queryStrings(t, db, `SELECT ID || ' ' || Code FROM Currencies ORDER BY ID`)
+	if want := []string{"1 USD", "2 EUR", "3 USD"}; !slices.Equal(currencies, want) {
+		t.Errorf("Currencies after Down() = %v, want the dropped duplicate back %v", currencies, want)
+	}
+	rates := queryStrings(t, db, `SELECT BaseCurrencyCode || TargetCurrencyCode || ' ' || Rate FROM ExchangeRates ORDER BY ID`)
+	if want := []string{"USDEUR 0.9123", "EURUSD 1.0961", "USDEUR 0.5", "USDGBP 0.79"}; !slices.Equal(rates, want) {
+		t.Errorf("ExchangeRates after Down() = %v, want the dropped rates back %v", rates, want)
+	}
+	scheduled := queryStrings(t, db, `SELECT ID || ' ' || BaseCurrencyCode || TargetCurrencyCode FROM ScheduledExchangeRates ORDER BY ID`)
+	if want := []string{"1 USDEUR", "2 USDGBP"}; !slices.Equal(scheduled, want) {
+		t.Errorf("ScheduledExchangeRates after Down() = %v, want the dropped rate back %v", scheduled, want)
+	}
+	if tables := queryStrings(t, db, `SELECT name FROM sqlite_master WHERE name LIKE '%_migration_dropped'`); len(tables) != 0 {
+		t.Errorf("%v are left after Down()", tables)
+	}
+
+	if _, err := db.Exec(`INSERT INTO Currencies (Code, FullName, Sign) VALUES ('EUR', 'Euro', '€')`); err != nil {
+		t.Errorf("Currencies codes are still unique after reverting the migration: %v", err)
+	}
+	// ExchangeRates has no foreign keys after Down(): the baseline ones
+	// referenced Currencies(ID) and rejected every rate with enforcement on.
+	if _, err := db.Exec(`INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate) VALUES ('EUR', 'USD', '1.1')`); err != nil {
+		t.Errorf("a rate cannot be stored after reverting the migration: %v", err)
+	}
+
+	if applied, err := migrator.Up(ctx); err != nil || len(applied) != len(sqlite)-1 {
+		t.Fatalf("Up() after Down() = %d migrations, %v, want %d", len(applied), err, len(sqlite)-1)
+	}
+	for version := len(sqlite); version > 1; version-- {
+		if _, err := migrator.Down(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := migrator.Down(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Down(ctx); !errors.Is(err, ErrorNoMigrationToRevert) {
+		t.Errorf("Down() without applied migrations error = %v, want %v", err, ErrorNoMigrationToRevert)
+	}
+}
+
+func TestSQLiteMigrationsCancelled(t *testing.T) {
+	db := openLegacySQLite(t)
+	migrator := NewSQLite(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if applied, err := migrator.Up(ctx); !errors.Is(err, context.Canceled) || len(applied) != 0 {
+		t.Errorf("Up() with a cancelled context = %d migrations, %v, want none and %v", len(applied), err, context.Canceled)
+	}
+
+	if version, err := migrator.Version(context.Background()); err != nil || version != 0 {
+		t.Errorf("Version() after a cancelled Up() = %d, %v, want 0", version, err)
+	}
+	if _, err := db.Exec(`INSERT INTO Currencies (Code, FullName, Sign) VALUES ('EUR', 'Euro', '€')`); err != nil {
+		t.Errorf("the legacy schema changed after a cancelled Up(): %v", err)
+	}
+}
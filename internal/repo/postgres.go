@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+
+	_ "github.com/lib/pq"
+)
+
+// NewPostgresDB connects to the Postgres database at dsn. Its schema is
+// managed by migrations.NewPostgres.
+func NewPostgresDB(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+
+	if err != nil {
+		return nil, errors.New("Error connecting to database")
+	}
+
+	return db, nil
+}
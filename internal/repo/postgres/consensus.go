@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// SaveProviderQuote keeps the latest quote of every provider per pair and
+// effective time.
+func (repo *Repo) SaveProviderQuote(ctx context.Context, quote models.ProviderQuote) error {
+	query := `
+		INSERT INTO ProviderQuotes (Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, ObservedAt)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (Provider, BaseCurrencyCode, TargetCurrencyCode, EffectiveAt)
+		DO UPDATE SET Rate = excluded.Rate, ObservedAt = excluded.ObservedAt
+	`
+	_, err := repo.db.ExecContext(ctx, query,
+		quote.Provider,
+		quote.BaseCurrencyCode,
+		quote.TargetCurrencyCode,
+		quote.Rate,
+		effectiveTime(quote.EffectiveAt),
+		unixTime(quote.ObservedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetProviderQuotes returns the quotes of the pair in either direction that
+// take effect at effectiveAt and were observed since the given time.
+func (repo *Repo) GetProviderQuotes(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, effectiveAt, since time.Time) ([]models.ProviderQuote, error) {
+	query := `
+		SELECT Provider, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, ObservedAt FROM ProviderQuotes
+		WHERE ((BaseCurrencyCode = $1 AND TargetCurrencyCode = $2) OR (BaseCurrencyCode = $2 AND TargetCurrencyCode = $1))
+		AND EffectiveAt = $3 AND ObservedAt >= $4
+		ORDER BY Provider
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, codeBaseCurrency, codeTargetCurrency, effectiveTime(effectiveAt), unixTime(since))
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]models.ProviderQuote, 0, 4)
+	defer result.Close()
+
+	for result.Next() {
+		quote := models.ProviderQuote{}
+		if err := result.Scan(&quote.Provider, &quote.BaseCurrencyCode, &quote.TargetCurrencyCode, &quote.Rate, &quote.EffectiveAt, &quote.ObservedAt); err != nil {
+			return nil, err
+		}
+		quote.EffectiveAt = quote.EffectiveAt.UTC()
+		quote.ObservedAt = quote.ObservedAt.UTC()
+		quotes = append(quotes, quote)
+	}
+
+	return quotes, result.Err()
+}
+
+// SaveRateConsensus replaces the stored consensus of the pair unless the
+// stored one takes effect later, so backfilling history keeps the latest.
+func (repo *Repo) SaveRateConsensus(ctx context.Context, consensus models.RateConsensus) error {
+	contributions, err := json.Marshal(consensus.Contributions)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO RateConsensus (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, Method, MaxDeviation, ComputedAt, Contributions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (BaseCurrencyCode, TargetCurrencyCode)
+		DO UPDATE SET Rate = excluded.Rate, EffectiveAt = excluded.EffectiveAt, Method = excluded.Method,
+			MaxDeviation = excluded.MaxDeviation, ComputedAt = excluded.ComputedAt, Contributions = excluded.Contributions
+		WHERE excluded.EffectiveAt >= RateConsensus.EffectiveAt
+	`
+	_, err = repo.db.ExecContext(ctx, query,
+		consensus.BaseCurrencyCode,
+		consensus.TargetCurrencyCode,
+		consensus.Rate,
+		effectiveTime(consensus.EffectiveAt),
+		consensus.Method,
+		consensus.MaxDeviation,
+		unixTime(consensus.ComputedAt),
+		string(contributions),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GET /admin/consensus/USDRUB
+func (repo *Repo) GetRateConsensus(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.RateConsensus, error) {
+	query := `
+		SELECT BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, Method, MaxDeviation, ComputedAt, Contributions FROM RateConsensus
+		WHERE BaseCurrencyCode = $1 AND TargetCurrencyCode = $2
+	`
+
+	var contributions string
+	consensus := models.RateConsensus{}
+	err := repo.db.QueryRowContext(ctx, query, codeBaseCurrency, codeTargetCurrency).Scan(
+		&consensus.BaseCurrencyCode,
+		&consensus.TargetCurrencyCode,
+		&consensus.Rate,
+		&consensus.EffectiveAt,
+		&consensus.Method,
+		&consensus.MaxDeviation,
+		&consensus.ComputedAt,
+		&contributions,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RateConsensus{}, models.ErrorConsensusNotFound
+		}
+		return models.RateConsensus{}, err
+	}
+
+	consensus.EffectiveAt = consensus.EffectiveAt.UTC()
+	consensus.ComputedAt = consensus.ComputedAt.UTC()
+	if err := json.Unmarshal([]byte(contributions), &consensus.Contributions); err != nil {
+		return models.RateConsensus{}, err
+	}
+
+	return consensus, nil
+}
+
+// effectiveTime truncates t like unixTime but keeps the zero time, which
+// stands for a rate effective immediately.
+func effectiveTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+
+	return unixTime(t)
+}
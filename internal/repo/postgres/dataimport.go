@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"time"
+)
+
+// POST /import
+// Import writes all currencies and rates in a single transaction so either
+// every row is stored or none. Currencies are matched by code and updated
+// when they exist; rates are applied like provider updates, future ones
+// are scheduled.
+func (repo *Repo) Import(ctx context.Context, currencies []models.Currency, rates []models.ExchangeRateImportRow) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, currency := range currencies {
+		query := `
+			INSERT INTO Currencies (Code, FullName, Sign, MinorUnits, RoundingMode)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT ON CONSTRAINT CurrenciesCodeKey
+			DO UPDATE SET FullName = excluded.FullName, Sign = excluded.Sign, MinorUnits = excluded.MinorUnits, RoundingMode = excluded.RoundingMode
+		`
+		if _, err := tx.ExecContext(ctx, query, currency.Code, currency.FullName, currency.Sign, currency.MinorUnits, currency.RoundingMode); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	for _, rate := range rates {
+		if rate.EffectiveAt.After(now) {
+			query := `
+				INSERT INTO ScheduledExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt, Status)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`
+			if _, err := tx.ExecContext(ctx, query, rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, unixTime(rate.EffectiveAt), unixTime(now), models.ScheduledRatePending); err != nil {
+				return mapError(err)
+			}
+			continue
+		}
+
+		effectiveFrom := rate.EffectiveAt
+		if effectiveFrom.IsZero() {
+			effectiveFrom = now
+		}
+
+		if err := activateRate(ctx, tx, rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, effectiveFrom, now); err != nil {
+			return err
+		}
+
+		if rate.Spread.Valid {
+			query := `
+				UPDATE ExchangeRates SET Spread = $1 WHERE BaseCurrencyCode = $2 AND TargetCurrencyCode = $3
+			`
+			if _, err := tx.ExecContext(ctx, query, rate.Spread.Decimal, rate.BaseCurrencyCode, rate.TargetCurrencyCode); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
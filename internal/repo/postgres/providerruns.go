@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"strings"
+)
+
+func (repo *Repo) AddProviderRun(ctx context.Context, run models.ProviderRun) (models.ProviderRun, error) {
+	query := `
+		INSERT INTO ProviderRuns (Provider, StartedAt, FinishedAt, RowsFetched, RowsChanged, RowsRejected, Errors)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ID
+	`
+	err := repo.db.QueryRowContext(ctx, query,
+		run.Provider,
+		unixTime(run.StartedAt),
+		unixTime(run.FinishedAt),
+		run.RowsFetched,
+		run.RowsChanged,
+		run.RowsRejected,
+		strings.Join(run.Errors, "\n"),
+	).Scan(&run.ID)
+	if err != nil {
+		return models.ProviderRun{}, err
+	}
+
+	return run, nil
+}
+
+// GET /admin/providerRuns
+// GetProviderRuns returns the latest runs first. An empty provider name
+// returns the runs of every provider.
+func (repo *Repo) GetProviderRuns(ctx context.Context, provider string, limit int) ([]models.ProviderRun, error) {
+	query := `
+		SELECT ID, Provider, StartedAt, FinishedAt, RowsFetched, RowsChanged, RowsRejected, Errors FROM ProviderRuns
+		WHERE $1 = '' OR Provider = $1
+		ORDER BY ID DESC
+		LIMIT $2
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, provider, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]models.ProviderRun, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		var errors string
+		run := models.ProviderRun{}
+		if err := result.Scan(&run.ID, &run.Provider, &run.StartedAt, &run.FinishedAt, &run.RowsFetched, &run.RowsChanged, &run.RowsRejected, &errors); err != nil {
+			return nil, err
+		}
+		run.StartedAt = run.StartedAt.UTC()
+		run.FinishedAt = run.FinishedAt.UTC()
+		run.Errors = make([]string, 0)
+		if errors != "" {
+			run.Errors = strings.Split(errors, "\n")
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, result.Err()
+}
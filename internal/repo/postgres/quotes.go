@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// POST /quotes
+func (repo *Repo) AddQuote(ctx context.Context, quote models.Quote) (models.Quote, error) {
+	quote.CreatedAt = unixTime(quote.CreatedAt)
+	quote.ExpiresAt = unixTime(quote.ExpiresAt)
+	quote.Status = models.QuoteOpen
+
+	query := `
+		INSERT INTO Quotes (BaseCurrencyCode, TargetCurrencyCode, Amount, Rate, ConvertedAmount, Fee, NetAmount, Side, ClientID, CreatedAt, ExpiresAt, Status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING ID
+	`
+	err := repo.db.QueryRowContext(ctx, query,
+		quote.BaseCurrencyCode,
+		quote.TargetCurrencyCode,
+		quote.Amount,
+		quote.Rate,
+		quote.ConvertedAmount,
+		quote.Fee,
+		quote.NetAmount,
+		quote.Side,
+		quote.ClientID,
+		quote.CreatedAt,
+		quote.ExpiresAt,
+		quote.Status,
+	).Scan(&quote.ID)
+	if err != nil {
+		return models.Quote{}, mapError(err)
+	}
+
+	return quote, nil
+}
+
+// GET /quotes/1
+func (repo *Repo) GetQuote(ctx context.Context, id int) (models.Quote, error) {
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Amount, Rate, ConvertedAmount, Fee, NetAmount, Side, ClientID, CreatedAt, ExpiresAt, ExecutedAt, Status FROM Quotes
+		WHERE ID = $1
+	`
+
+	var (
+		quote      models.Quote
+		executedAt sql.NullTime
+	)
+	err := repo.db.QueryRowContext(ctx, query, id).Scan(
+		&quote.ID,
+		&quote.BaseCurrencyCode,
+		&quote.TargetCurrencyCode,
+		&quote.Amount,
+		&quote.Rate,
+		&quote.ConvertedAmount,
+		&quote.Fee,
+		&quote.NetAmount,
+		&quote.Side,
+		&quote.ClientID,
+		&quote.CreatedAt,
+		&quote.ExpiresAt,
+		&executedAt,
+		&quote.Status,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Quote{}, models.ErrorQuoteNotFound
+		}
+		return models.Quote{}, err
+	}
+
+	quote.CreatedAt = quote.CreatedAt.UTC()
+	quote.ExpiresAt = quote.ExpiresAt.UTC()
+	if executedAt.Valid {
+		executed := executedAt.Time.UTC()
+		quote.ExecutedAt = &executed
+	}
+
+	return quote, nil
+}
+
+// POST /quotes/1/execute
+// ExecuteQuote marks an open quote that has not expired at the given moment as
+// executed. A quote can only be executed once, even by concurrent requests.
+func (repo *Repo) ExecuteQuote(ctx context.Context, id int, at time.Time) (models.Quote, error) {
+	query := `
+		UPDATE Quotes SET Status = $1, ExecutedAt = $2 WHERE ID = $3 AND Status = $4 AND ExpiresAt >= $2
+	`
+
+	result, err := repo.db.ExecContext(ctx, query, models.QuoteExecuted, unixTime(at), id, models.QuoteOpen)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	executed, err := result.RowsAffected()
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	quote, err := repo.GetQuote(ctx, id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	if executed == 0 {
+		if quote.Status == models.QuoteExecuted {
+			return models.Quote{}, models.ErrorQuoteAlreadyExecuted
+		}
+		return models.Quote{}, models.ErrorQuoteExpired
+	}
+
+	return quote, nil
+}
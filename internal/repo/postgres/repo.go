@@ -0,0 +1,487 @@
+// Package postgres is the Postgres backed repository of the service. It has
+// the semantics of the SQLite backed currencies.Repo but relies on the
+// constraints of the schema created by migrations.NewPostgres: violated foreign
+// keys and unique constraints are reported as the matching domain errors.
+package postgres
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+// Codes of the Postgres errors mapped to domain errors.
+const (
+	foreignKeyViolation pq.ErrorCode = "23503"
+	uniqueViolation     pq.ErrorCode = "23505"
+)
+
+type Repo struct {
+	db *sql.DB
+}
+
+func NewRepo(db *sql.DB) *Repo {
+	return &Repo{db: db}
+}
+
+// mapError turns constraint violations into domain errors: a missing
+// currency violates a foreign key, while a second currency with a stored code
+// or a second row for a stored pair violates a unique constraint.
+func mapError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch {
+	case pqErr.Code == foreignKeyViolation:
+		return models.ErrorCurrencyNotFound
+	case pqErr.Code == uniqueViolation && pqErr.Constraint == "currenciescodekey":
+		return models.ErrorCurrencyAlreadyExists
+	case pqErr.Code == uniqueViolation && pqErr.Constraint == "exchangeratespairkey":
+		return models.ErrorExchangeRateAlreadyExists
+	}
+
+	return err
+}
+
+// unixTime truncates t to whole seconds, the precision the SQLite backend
+// stores, so both backends compare and return the same times.
+func unixTime(t time.Time) time.Time {
+	return time.Unix(t.Unix(), 0).UTC()
+}
+
+// POST /currencies
+func (repo *Repo) AddCurrency(ctx context.Context, currency models.Currency) error {
+	query := `
+		INSERT INTO Currencies (Code, FullName, Sign, MinorUnits, RoundingMode)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := repo.db.ExecContext(ctx, query, currency.Code, currency.FullName, currency.Sign, currency.MinorUnits, currency.RoundingMode); err != nil {
+		return mapError(err)
+	}
+
+	return nil
+}
+
+func (repo *Repo) GetCurrencyByCode(ctx context.Context, code string) (models.Currency, error) {
+	query := `
+		SELECT ID, Code, FullName, Sign, MinorUnits, RoundingMode FROM Currencies
+		WHERE Code = $1
+	`
+	currency := models.Currency{}
+	err := repo.db.QueryRowContext(ctx, query, code).Scan(&currency.ID, &currency.Code, &currency.FullName, &currency.Sign, &currency.MinorUnits, &currency.RoundingMode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Currency{}, models.ErrorCurrencyNotFound
+		}
+		return models.Currency{}, err
+	}
+	return currency, nil
+}
+
+// GET /currencies
+func (repo *Repo) GetCurrencies(ctx context.Context) ([]models.Currency, error) {
+	query := `
+		SELECT ID, Code, FullName, Sign, MinorUnits, RoundingMode FROM Currencies
+		ORDER BY ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := make([]models.Currency, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		currency := models.Currency{}
+		if err := result.Scan(&currency.ID, &currency.Code, &currency.FullName, &currency.Sign, &currency.MinorUnits, &currency.RoundingMode); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, currency)
+	}
+
+	return currencies, result.Err()
+}
+
+// GET /exchangeRates
+func (repo *Repo) GetExchangeRates(ctx context.Context) ([]models.CurrencyExchange, error) {
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, Spread, UpdatedAt FROM ExchangeRates
+		ORDER BY ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangerates := make([]models.CurrencyExchange, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		exchangerate := models.CurrencyExchange{}
+		if err := result.Scan(&exchangerate.ID, &exchangerate.BaseCurrencyCode, &exchangerate.TargetCurrencyCode, &exchangerate.Rate, &exchangerate.Spread, &exchangerate.UpdatedAt); err != nil {
+			return nil, err
+		}
+		exchangerate.UpdatedAt = exchangerate.UpdatedAt.UTC()
+		exchangerates = append(exchangerates, exchangerate)
+	}
+
+	return exchangerates, result.Err()
+}
+
+// GET /exchangeRate/USDRUB
+func (repo *Repo) GetExchangeRateByCodesPair(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.CurrencyExchange, error) {
+	if err := repo.checkCurrencies(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return models.CurrencyExchange{}, err
+	}
+
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, Spread, UpdatedAt FROM ExchangeRates
+		WHERE BaseCurrencyCode = $1 AND TargetCurrencyCode = $2
+	`
+
+	exchangerate := models.CurrencyExchange{}
+	err := repo.db.QueryRowContext(ctx, query, codeBaseCurrency, codeTargetCurrency).Scan(
+		&exchangerate.ID,
+		&exchangerate.BaseCurrencyCode,
+		&exchangerate.TargetCurrencyCode,
+		&exchangerate.Rate,
+		&exchangerate.Spread,
+		&exchangerate.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.CurrencyExchange{}, models.ErrorExchangeRateNotFound
+		}
+		return models.CurrencyExchange{}, err
+	}
+	exchangerate.UpdatedAt = exchangerate.UpdatedAt.UTC()
+
+	return exchangerate, nil
+}
+
+// checkCurrencies reports ErrorCurrencyNotFound for the first unknown code.
+func (repo *Repo) checkCurrencies(ctx context.Context, codes ...string) error {
+	for _, code := range codes {
+		if _, err := repo.GetCurrencyByCode(ctx, code); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// POST /exchangeRates
+// A valid inverse is written to the reverse pair, if it is stored, in the same transaction.
+func (repo *Repo) AddExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate, spread decimal.Decimal, inverse decimal.NullDecimal) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := unixTime(time.Now())
+	query := `
+		INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate, Spread, UpdatedAt)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := tx.ExecContext(ctx, query, codeBaseCurrency, codeTargetCurrency, rate, spread, now); err != nil {
+		return mapError(err)
+	}
+
+	if err := addHistory(ctx, tx, codeBaseCurrency, codeTargetCurrency, rate, now); err != nil {
+		return err
+	}
+
+	if err := updateInverse(ctx, tx, codeBaseCurrency, codeTargetCurrency, inverse, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// PATCH /exchangeRate/USDRUB
+// An invalid spread keeps the spread the pair already has. A valid inverse is
+// written to the reverse pair, if it is stored, in the same transaction.
+func (repo *Repo) UpdateExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, newRate decimal.Decimal, spread, inverse decimal.NullDecimal) error {
+	if err := repo.checkCurrencies(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return err
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := unixTime(time.Now())
+	query := `
+		UPDATE ExchangeRates SET Rate = $1, Spread = COALESCE($2, Spread), UpdatedAt = $3 WHERE BaseCurrencyCode = $4 AND TargetCurrencyCode = $5
+	`
+
+	result, err := tx.ExecContext(ctx, query, newRate, spread, now, codeBaseCurrency, codeTargetCurrency)
+	if err != nil {
+		return err
+	}
+
+	if updated, err := result.RowsAffected(); err != nil {
+		return err
+	} else if updated == 0 {
+		return models.ErrorExchangeRateNotFound
+	}
+
+	if err := addHistory(ctx, tx, codeBaseCurrency, codeTargetCurrency, newRate, now); err != nil {
+		return err
+	}
+
+	if err := updateInverse(ctx, tx, codeBaseCurrency, codeTargetCurrency, inverse, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TouchExchangeRate marks the current value of the pair as confirmed at the
+// given moment without recording a new history entry.
+func (repo *Repo) TouchExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, at time.Time) error {
+	query := `
+		UPDATE ExchangeRates SET UpdatedAt = $1 WHERE BaseCurrencyCode = $2 AND TargetCurrencyCode = $3 AND UpdatedAt < $1
+	`
+	if _, err := repo.db.ExecContext(ctx, query, unixTime(at), codeBaseCurrency, codeTargetCurrency); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// updateInverse sets the TARGET→BASE pair to a valid inverse, keeping its spread.
+func updateInverse(ctx context.Context, tx *sql.Tx, codeBaseCurrency, codeTargetCurrency string, inverse decimal.NullDecimal, now time.Time) error {
+	if !inverse.Valid {
+		return nil
+	}
+
+	query := `
+		UPDATE ExchangeRates SET Rate = $1, UpdatedAt = $2 WHERE BaseCurrencyCode = $3 AND TargetCurrencyCode = $4
+	`
+	result, err := tx.ExecContext(ctx, query, inverse.Decimal, now, codeTargetCurrency, codeBaseCurrency)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return nil
+	}
+
+	return addHistory(ctx, tx, codeTargetCurrency, codeBaseCurrency, inverse.Decimal, now)
+}
+
+func addHistory(ctx context.Context, tx *sql.Tx, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveFrom time.Time) error {
+	query := `
+		INSERT INTO ExchangeRateHistory (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveFrom)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := tx.ExecContext(ctx, query, codeBaseCurrency, codeTargetCurrency, rate, unixTime(effectiveFrom)); err != nil {
+		return mapError(err)
+	}
+
+	return nil
+}
+
+// GetLatestRateChange returns when the latest rate change that took effect at
+// or before at did, the zero time when there is none. GetExchangeRatesAt
+// returns the same rates for every moment with the same latest change.
+func (repo *Repo) GetLatestRateChange(ctx context.Context, at time.Time) (time.Time, error) {
+	query := `
+		SELECT MAX(EffectiveFrom) FROM ExchangeRateHistory WHERE EffectiveFrom <= $1
+	`
+
+	var effectiveFrom sql.NullTime
+	if err := repo.db.QueryRowContext(ctx, query, unixTime(at)).Scan(&effectiveFrom); err != nil {
+		return time.Time{}, err
+	}
+
+	if !effectiveFrom.Valid {
+		return time.Time{}, nil
+	}
+
+	return effectiveFrom.Time.UTC(), nil
+}
+
+// GET /exchange?date=2024-01-31
+// GetExchangeRatesAt returns every pair with the rate that was in effect at the given moment.
+// Spreads are not versioned, so every pair carries its current spread.
+func (repo *Repo) GetExchangeRatesAt(ctx context.Context, at time.Time) ([]models.CurrencyExchange, error) {
+	query := `
+		SELECT e.ID, h.BaseCurrencyCode, h.TargetCurrencyCode, h.Rate, e.Spread, h.EffectiveFrom FROM ExchangeRates e
+		JOIN LATERAL (
+			SELECT latest.BaseCurrencyCode, latest.TargetCurrencyCode, latest.Rate, latest.EffectiveFrom FROM ExchangeRateHistory latest
+			WHERE latest.BaseCurrencyCode = e.BaseCurrencyCode AND latest.TargetCurrencyCode = e.TargetCurrencyCode
+			AND latest.EffectiveFrom <= $1
+			ORDER BY latest.EffectiveFrom DESC, latest.ID DESC LIMIT 1
+		) h ON TRUE
+		ORDER BY e.ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, unixTime(at))
+	if err != nil {
+		return nil, err
+	}
+
+	exchangerates := make([]models.CurrencyExchange, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		exchangerate := models.CurrencyExchange{}
+		if err := result.Scan(&exchangerate.ID, &exchangerate.BaseCurrencyCode, &exchangerate.TargetCurrencyCode, &exchangerate.Rate, &exchangerate.Spread, &exchangerate.UpdatedAt); err != nil {
+			return nil, err
+		}
+		exchangerate.UpdatedAt = exchangerate.UpdatedAt.UTC()
+		exchangerates = append(exchangerates, exchangerate)
+	}
+
+	return exchangerates, result.Err()
+}
+
+// GET /exchangeRate/USDRUB/history?from=&to=
+func (repo *Repo) GetExchangeRateHistory(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) ([]models.HistoricalRate, error) {
+	if _, err := repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveFrom FROM ExchangeRateHistory
+		WHERE BaseCurrencyCode = $1 AND TargetCurrencyCode = $2 AND EffectiveFrom >= $3 AND EffectiveFrom <= $4
+		ORDER BY EffectiveFrom, ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, codeBaseCurrency, codeTargetCurrency, unixTime(from), unixTime(to))
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.HistoricalRate, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		rate := models.HistoricalRate{}
+		if err := result.Scan(&rate.ID, &rate.BaseCurrencyCode, &rate.TargetCurrencyCode, &rate.Rate, &rate.EffectiveFrom); err != nil {
+			return nil, err
+		}
+		rate.EffectiveFrom = rate.EffectiveFrom.UTC()
+		history = append(history, rate)
+	}
+
+	return history, result.Err()
+}
+
+// GET /exchangeRate/USDRUB/ohlc?interval=1d
+// GetExchangeRateOHLC buckets the history of a pair into intervals aligned to
+// the Unix epoch. Buckets without rate changes are omitted.
+func (repo *Repo) GetExchangeRateOHLC(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time, interval time.Duration) ([]models.RateCandle, error) {
+	if _, err := repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH h AS (
+			SELECT CAST(EXTRACT(EPOCH FROM EffectiveFrom) AS BIGINT) / $1 * $1 AS Bucket, EffectiveFrom, ID, Rate
+			FROM ExchangeRateHistory
+			WHERE BaseCurrencyCode = $2 AND TargetCurrencyCode = $3 AND EffectiveFrom >= $4 AND EffectiveFrom <= $5
+		)
+		SELECT DISTINCT Bucket,
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY EffectiveFrom, ID),
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY Rate DESC, EffectiveFrom, ID),
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY Rate, EffectiveFrom, ID),
+			FIRST_VALUE(Rate) OVER (PARTITION BY Bucket ORDER BY EffectiveFrom DESC, ID DESC),
+			COUNT(*) OVER (PARTITION BY Bucket)
+		FROM h
+		ORDER BY Bucket
+	`
+
+	seconds := int64(interval / time.Second)
+	result, err := repo.db.QueryContext(ctx, query, seconds, codeBaseCurrency, codeTargetCurrency, unixTime(from), unixTime(to))
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]models.RateCandle, 0, 10)
+	defer result.Close()
+
+	for result.Next() {
+		var bucket int64
+		candle := models.RateCandle{}
+		if err := result.Scan(&bucket, &candle.Open, &candle.High, &candle.Low, &candle.Close, &candle.Count); err != nil {
+			return nil, err
+		}
+		candle.Start = time.Unix(bucket, 0).UTC()
+		candles = append(candles, candle)
+	}
+
+	return candles, result.Err()
+}
+
+// GetExchangeRateStatistics computes min, max, mean, population standard
+// deviation and percent change of a pair's history within the range.
+func (repo *Repo) GetExchangeRateStatistics(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) (models.RateStatistics, error) {
+	if _, err := repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return models.RateStatistics{}, err
+	}
+
+	query := `
+		WITH h AS (
+			SELECT EffectiveFrom, ID, Rate
+			FROM ExchangeRateHistory
+			WHERE BaseCurrencyCode = $1 AND TargetCurrencyCode = $2 AND EffectiveFrom >= $3 AND EffectiveFrom <= $4
+		)
+		SELECT COUNT(*), AVG(Rate), SQRT(VAR_POP(Rate)),
+			(SELECT Rate FROM h ORDER BY Rate, EffectiveFrom, ID LIMIT 1),
+			(SELECT Rate FROM h ORDER BY Rate DESC, EffectiveFrom, ID LIMIT 1),
+			(SELECT Rate FROM h ORDER BY EffectiveFrom, ID LIMIT 1),
+			(SELECT Rate FROM h ORDER BY EffectiveFrom DESC, ID DESC LIMIT 1)
+		FROM h
+	`
+
+	var (
+		mean   decimal.NullDecimal
+		stdDev decimal.NullDecimal
+		min    decimal.NullDecimal
+		max    decimal.NullDecimal
+		first  decimal.NullDecimal
+		last   decimal.NullDecimal
+	)
+
+	statistics := models.RateStatistics{From: from.UTC(), To: to.UTC()}
+	err := repo.db.QueryRowContext(ctx, query, codeBaseCurrency, codeTargetCurrency, unixTime(from), unixTime(to)).Scan(
+		&statistics.Count, &mean, &stdDev, &min, &max, &first, &last,
+	)
+	if err != nil {
+		return models.RateStatistics{}, err
+	}
+
+	if statistics.Count == 0 {
+		return statistics, nil
+	}
+
+	statistics.Min = min.Decimal
+	statistics.Max = max.Decimal
+	statistics.Mean = mean.Decimal.Round(models.RatePrecision)
+	statistics.StdDev = stdDev.Decimal.Round(models.RatePrecision)
+	statistics.First = first.Decimal
+	statistics.Last = last.Decimal
+	statistics.PercentChange = models.PercentChange(first.Decimal, last.Decimal)
+
+	return statistics, nil
+}
@@ -0,0 +1,276 @@
+package postgres
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"currencyservice/internal/repo/migrations"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+)
+
+// testDSNVariable names the environment variable holding the connection
+// string of a Postgres database the tests may write to. Tests needing a
+// database are skipped when it is not set; README.md shows how to start one
+// with docker-compose.yml.
+const testDSNVariable = "POSTGRES_TEST_DSN"
+
+// openTestDB connects to the test database inside a schema of its own, so
+// tests and packages running at the same time do not see each other's rows.
+// The schema is dropped when the test ends.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNVariable)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNVariable)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	separator := " "
+	if strings.Contains(dsn, "://") {
+		separator = "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+	}
+	db, err := sql.Open("postgres", dsn+separator+"search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newTestRepo(t *testing.T) *Repo {
+	t.Helper()
+
+	db := openTestDB(t)
+	if _, err := migrations.NewPostgres(db).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewRepo(db)
+}
+
+func addTestCurrencies(t *testing.T, repo *Repo, codes ...string) {
+	t.Helper()
+
+	for _, code := range codes {
+		currency := models.Currency{Code: code, FullName: code, Sign: code, MinorUnits: 2, RoundingMode: models.RoundingHalfUp}
+		if err := repo.AddCurrency(context.Background(), currency); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMapError(t *testing.T) {
+	other := errors.New("connection refused")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "foreign key", err: &pq.Error{Code: foreignKeyViolation, Constraint: "exchangerates_basecurrencycode_fkey"}, want: models.ErrorCurrencyNotFound},
+		{name: "currency code", err: &pq.Error{Code: uniqueViolation, Constraint: "currenciescodekey"}, want: models.ErrorCurrencyAlreadyExists},
+		{name: "rate pair", err: &pq.Error{Code: uniqueViolation, Constraint: "exchangeratespairkey"}, want: models.ErrorExchangeRateAlreadyExists},
+		{name: "wrapped", err: fmt.Errorf("insert: %w", &pq.Error{Code: foreignKeyViolation}), want: models.ErrorCurrencyNotFound},
+		{name: "other unique constraint", err: &pq.Error{Code: uniqueViolation, Constraint: "providerquotes_pkey"}},
+		{name: "other error", err: other, want: other},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := mapError(test.err)
+			if test.want == nil {
+				if got != test.err {
+					t.Errorf("mapError() = %v, want it unchanged", got)
+				}
+				return
+			}
+			if !errors.Is(got, test.want) {
+				t.Errorf("mapError() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCurrencies(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	addTestCurrencies(t, repo, "USD", "EUR")
+
+	currency, err := repo.GetCurrencyByCode(ctx, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if currency.Code != "EUR" || currency.MinorUnits != 2 || currency.RoundingMode != models.RoundingHalfUp {
+		t.Errorf("GetCurrencyByCode(EUR) = %+v", currency)
+	}
+
+	currencies, err := repo.GetCurrencies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(currencies) != 2 || currencies[0].Code != "USD" || currencies[1].Code != "EUR" {
+		t.Errorf("GetCurrencies() = %+v, want USD and EUR", currencies)
+	}
+
+	if _, err := repo.GetCurrencyByCode(ctx, "GBP"); !errors.Is(err, models.ErrorCurrencyNotFound) {
+		t.Errorf("GetCurrencyByCode(GBP) error = %v, want %v", err, models.ErrorCurrencyNotFound)
+	}
+
+	err = repo.AddCurrency(ctx, models.Currency{Code: "USD", FullName: "Dollar", Sign: "$", MinorUnits: 2, RoundingMode: models.RoundingHalfUp})
+	if !errors.Is(err, models.ErrorCurrencyAlreadyExists) {
+		t.Errorf("AddCurrency(USD) twice error = %v, want %v", err, models.ErrorCurrencyAlreadyExists)
+	}
+}
+
+func TestExchangeRates(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	addTestCurrencies(t, repo, "USD", "EUR")
+
+	rate := decimal.RequireFromString("0.9123456789012345678")
+	if err := repo.AddExchangeRate(ctx, "USD", "EUR", rate, decimal.RequireFromString("0.02"), decimal.NullDecimal{}); err != nil {
+		t.Fatal(err)
+	}
+
+	exchangerate, err := repo.GetExchangeRateByCodesPair(ctx, "USD", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exchangerate.Rate.Equal(rate) || !exchangerate.Spread.Equal(decimal.RequireFromString("0.02")) {
+		t.Errorf("GetExchangeRateByCodesPair(USD, EUR) = %s spread %s, want %s spread 0.02", exchangerate.Rate, exchangerate.Spread, rate)
+	}
+
+	err = repo.AddExchangeRate(ctx, "USD", "EUR", rate, decimal.Zero, decimal.NullDecimal{})
+	if !errors.Is(err, models.ErrorExchangeRateAlreadyExists) {
+		t.Errorf("AddExchangeRate(USD, EUR) twice error = %v, want %v", err, models.ErrorExchangeRateAlreadyExists)
+	}
+
+	err = repo.AddExchangeRate(ctx, "USD", "GBP", rate, decimal.Zero, decimal.NullDecimal{})
+	if !errors.Is(err, models.ErrorCurrencyNotFound) {
+		t.Errorf("AddExchangeRate(USD, GBP) error = %v, want %v", err, models.ErrorCurrencyNotFound)
+	}
+
+	if err := repo.AddExchangeRate(ctx, "EUR", "USD", decimal.RequireFromString("1.1"), decimal.Zero, decimal.NullDecimal{}); err != nil {
+		t.Fatal(err)
+	}
+
+	newRate := decimal.RequireFromString("0.95")
+	inverse := decimal.NewNullDecimal(decimal.NewFromInt(1).DivRound(newRate, models.RatePrecision))
+	if err := repo.UpdateExchangeRate(ctx, "USD", "EUR", newRate, decimal.NullDecimal{}, inverse); err != nil {
+		t.Fatal(err)
+	}
+
+	exchangerates, err := repo.GetExchangeRates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchangerates) != 2 {
+		t.Fatalf("GetExchangeRates() = %+v, want 2 rates", exchangerates)
+	}
+	if got := exchangerates[0]; !got.Rate.Equal(newRate) || !got.Spread.Equal(decimal.RequireFromString("0.02")) {
+		t.Errorf("USD→EUR = %s spread %s, want %s keeping spread 0.02", got.Rate, got.Spread, newRate)
+	}
+	if got := exchangerates[1]; !got.Rate.Equal(inverse.Decimal) {
+		t.Errorf("EUR→USD = %s, want the inverse %s", got.Rate, inverse.Decimal)
+	}
+
+	history, err := repo.GetExchangeRateHistory(ctx, "USD", "EUR", time.Time{}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Errorf("GetExchangeRateHistory(USD, EUR) = %+v, want 2 entries", history)
+	}
+
+	err = repo.UpdateExchangeRate(ctx, "EUR", "EUR", newRate, decimal.NullDecimal{}, decimal.NullDecimal{})
+	if !errors.Is(err, models.ErrorExchangeRateNotFound) {
+		t.Errorf("UpdateExchangeRate(EUR, EUR) error = %v, want %v", err, models.ErrorExchangeRateNotFound)
+	}
+}
+
+func TestPostgresMigrations(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrator := migrations.NewPostgres(db)
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) == 0 {
+		t.Fatal("Up() applied nothing on an empty database")
+	}
+	if err := migrator.Check(ctx); err != nil {
+		t.Errorf("Check() after Up() error = %v", err)
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if !status.Applied || status.AppliedAt.IsZero() {
+			t.Errorf("Status() after Up() = %+v, want applied", status)
+		}
+	}
+
+	if again, err := migrator.Up(ctx); err != nil || len(again) != 0 {
+		t.Errorf("Up() twice = %d migrations, %v, want none", len(again), err)
+	}
+
+	for range applied {
+		if _, err := migrator.Down(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	statuses, err = migrator.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("Status() after Down() = %+v, want pending", status)
+		}
+	}
+
+	var table sql.NullString
+	if err := db.QueryRow("SELECT to_regclass('exchangerates')::text").Scan(&table); err != nil {
+		t.Fatal(err)
+	}
+	if table.Valid {
+		t.Errorf("ExchangeRates still exists after reverting every migration")
+	}
+
+	if _, err := migrator.Down(ctx); !errors.Is(err, migrations.ErrorNoMigrationToRevert) {
+		t.Errorf("Down() on an empty database error = %v, want %v", err, migrations.ErrorNoMigrationToRevert)
+	}
+}
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// POST /exchangeRates with effectiveAt
+func (repo *Repo) AddScheduledExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveAt time.Time) (models.ScheduledExchangeRate, error) {
+	scheduled := models.ScheduledExchangeRate{
+		BaseCurrencyCode:   codeBaseCurrency,
+		TargetCurrencyCode: codeTargetCurrency,
+		Rate:               rate,
+		EffectiveAt:        unixTime(effectiveAt),
+		CreatedAt:          unixTime(time.Now()),
+		Status:             models.ScheduledRatePending,
+	}
+
+	query := `
+		INSERT INTO ScheduledExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt, Status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ID
+	`
+	err := repo.db.QueryRowContext(ctx, query,
+		scheduled.BaseCurrencyCode,
+		scheduled.TargetCurrencyCode,
+		scheduled.Rate,
+		scheduled.EffectiveAt,
+		scheduled.CreatedAt,
+		scheduled.Status,
+	).Scan(&scheduled.ID)
+	if err != nil {
+		return models.ScheduledExchangeRate{}, mapError(err)
+	}
+
+	return scheduled, nil
+}
+
+// GET /exchangeRates/scheduled
+// Empty codes list the pending rates of every pair.
+func (repo *Repo) GetScheduledExchangeRates(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) ([]models.ScheduledExchangeRate, error) {
+	query := `
+		SELECT ID, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt, Status FROM ScheduledExchangeRates
+		WHERE Status = $1 AND ($2 = '' OR BaseCurrencyCode = $2) AND ($3 = '' OR TargetCurrencyCode = $3)
+		ORDER BY EffectiveAt, ID
+	`
+
+	result, err := repo.db.QueryContext(ctx, query, models.ScheduledRatePending, codeBaseCurrency, codeTargetCurrency)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return scanScheduledExchangeRates(result)
+}
+
+// DELETE /exchangeRates/scheduled/1
+func (repo *Repo) CancelScheduledExchangeRate(ctx context.Context, id int) error {
+	query := `
+		UPDATE ScheduledExchangeRates SET Status = $1 WHERE ID = $2 AND Status = $3
+	`
+
+	result, err := repo.db.ExecContext(ctx, query, models.ScheduledRateCancelled, id, models.ScheduledRatePending)
+	if err != nil {
+		return err
+	}
+
+	if cancelled, err := result.RowsAffected(); err != nil {
+		return err
+	} else if cancelled == 0 {
+		return models.ErrorScheduledRateNotFound
+	}
+
+	return nil
+}
+
+// ActivateScheduledExchangeRates applies every pending rate whose effective
+// time has passed: the pair is created if needed, the rate is added to its
+// history and the current rate is set to the latest value in effect. Rows are
+// locked so concurrent activations apply every rate once.
+func (repo *Repo) ActivateScheduledExchangeRates(ctx context.Context, now time.Time) (int, error) {
+	var due int
+	query := `
+		SELECT COUNT(*) FROM ScheduledExchangeRates WHERE Status = $1 AND EffectiveAt <= $2
+	`
+	if err := repo.db.QueryRowContext(ctx, query, models.ScheduledRatePending, unixTime(now)).Scan(&due); err != nil {
+		return 0, err
+	}
+
+	if due == 0 {
+		return 0, nil
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query = `
+		UPDATE ScheduledExchangeRates SET Status = $1
+		WHERE ID IN (
+			SELECT ID FROM ScheduledExchangeRates WHERE Status = $2 AND EffectiveAt <= $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ID, BaseCurrencyCode, TargetCurrencyCode, Rate, EffectiveAt, CreatedAt, Status
+	`
+	result, err := tx.QueryContext(ctx, query, models.ScheduledRateActive, models.ScheduledRatePending, unixTime(now))
+	if err != nil {
+		return 0, err
+	}
+
+	activated, err := scanScheduledExchangeRates(result)
+	result.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	sort.SliceStable(activated, func(i, j int) bool {
+		if !activated[i].EffectiveAt.Equal(activated[j].EffectiveAt) {
+			return activated[i].EffectiveAt.Before(activated[j].EffectiveAt)
+		}
+		return activated[i].ID < activated[j].ID
+	})
+
+	for _, scheduled := range activated {
+		if err := activateRate(ctx, tx, scheduled.BaseCurrencyCode, scheduled.TargetCurrencyCode, scheduled.Rate, scheduled.EffectiveAt, now); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(activated), nil
+}
+
+// activateRate records a rate that took effect at effectiveFrom, creating the
+// pair if it does not exist yet. Because effectiveFrom may lie in the past the
+// current rate is taken from the latest history entry rather than overwritten.
+func activateRate(ctx context.Context, tx *sql.Tx, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveFrom, now time.Time) error {
+	query := `
+		INSERT INTO ExchangeRates (BaseCurrencyCode, TargetCurrencyCode, Rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT ON CONSTRAINT ExchangeRatesPairKey DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, codeBaseCurrency, codeTargetCurrency, rate); err != nil {
+		return mapError(err)
+	}
+
+	if err := addHistory(ctx, tx, codeBaseCurrency, codeTargetCurrency, rate, effectiveFrom); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE ExchangeRates e SET Rate = h.Rate, UpdatedAt = h.EffectiveFrom
+		FROM (
+			SELECT Rate, EffectiveFrom FROM ExchangeRateHistory
+			WHERE BaseCurrencyCode = $1 AND TargetCurrencyCode = $2 AND EffectiveFrom <= $3
+			ORDER BY EffectiveFrom DESC, ID DESC LIMIT 1
+		) h
+		WHERE e.BaseCurrencyCode = $1 AND e.TargetCurrencyCode = $2
+	`
+	if _, err := tx.ExecContext(ctx, query, codeBaseCurrency, codeTargetCurrency, unixTime(now)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func scanScheduledExchangeRates(result *sql.Rows) ([]models.ScheduledExchangeRate, error) {
+	scheduledRates := make([]models.ScheduledExchangeRate, 0, 10)
+
+	for result.Next() {
+		scheduled := models.ScheduledExchangeRate{}
+		if err := result.Scan(
+			&scheduled.ID,
+			&scheduled.BaseCurrencyCode,
+			&scheduled.TargetCurrencyCode,
+			&scheduled.Rate,
+			&scheduled.EffectiveAt,
+			&scheduled.CreatedAt,
+			&scheduled.Status,
+		); err != nil {
+			return nil, err
+		}
+		scheduled.EffectiveAt = scheduled.EffectiveAt.UTC()
+		scheduled.CreatedAt = scheduled.CreatedAt.UTC()
+		scheduledRates = append(scheduledRates, scheduled)
+	}
+
+	return scheduledRates, result.Err()
+}
@@ -11,37 +11,15 @@ type Repo struct {
 	db *sql.DB
 }
 
+// NewDB opens the SQLite database of the service with foreign keys enforced.
+// Its schema is managed by migrations.NewSQLite.
 func NewDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "sqlite-database.db")
+	db, err := sql.Open("sqlite3", "sqlite-database.db?_busy_timeout=5000&_foreign_keys=on")
 
 	if err != nil {
 		return nil, errors.New("Error connecting to database")
 	}
 
-	currencies := `CREATE TABLE IF NOT EXISTS Currencies (
-    ID INTEGER PRIMARY KEY AUTOINCREMENT,
-    Code VARCHAR(10) NOT NULL,
-    FullName VARCHAR(100) NOT NULL,
-    Sign VARCHAR(10) NOT NULL
-	);`
-
-	if _, err := db.Exec(currencies); err != nil {
-		return nil, err
-	}
-
-	currencyexchange := `CREATE TABLE IF NOT EXISTS ExchangeRates (
-    ID INTEGER PRIMARY KEY AUTOINCREMENT,
-    BaseCurrencyCode VARCHAR(10) NOT NULL,
-    TargetCurrencyCode VARCHAR(10) NOT NULL,
-    Rate DECIMAL(6, 4) NOT NULL,
-    FOREIGN KEY (BaseCurrencyCode) REFERENCES Currencies(ID) ON DELETE CASCADE,
-    FOREIGN KEY (TargetCurrencyCode) REFERENCES Currencies(ID) ON DELETE CASCADE
-	);`
-
-	if _, err := db.Exec(currencyexchange); err != nil {
-		return nil, err
-	}
-
 	return db, nil
 }
 
@@ -0,0 +1,23 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+)
+
+// GetExchangeCurrenciesBatch converts every request in order. A failing
+// conversion is reported in its own result and does not stop the batch.
+func (usecase Usecase) GetExchangeCurrenciesBatch(ctx context.Context, requests []models.ExchangeRequest) ([]models.BatchExchangeResult, error) {
+	cache := newConversionCache(usecase.repo)
+	results := make([]models.BatchExchangeResult, 0, len(requests))
+	for _, request := range requests {
+		result, err := usecase.convert(ctx, cache, request)
+		if err != nil {
+			results = append(results, models.BatchExchangeResult{Error: err})
+			continue
+		}
+		results = append(results, models.BatchExchangeResult{Result: &result})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,94 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"time"
+)
+
+// conversionCache loads what conversions read from the repo on first use, so
+// a batch reads every currency, rate table and fee rule only once.
+type conversionCache struct {
+	repo       Repository
+	currencies map[string]models.Currency
+	rates      map[string]cachedRates
+	rules      []models.FeeRule
+}
+
+type cachedRates struct {
+	exchangerates []models.CurrencyExchange
+	graph         rateGraph
+}
+
+func newConversionCache(repo Repository) *conversionCache {
+	return &conversionCache{
+		repo:       repo,
+		currencies: make(map[string]models.Currency),
+		rates:      make(map[string]cachedRates),
+	}
+}
+
+func (cache *conversionCache) currency(ctx context.Context, code string) (models.Currency, error) {
+	if currency, ok := cache.currencies[code]; ok {
+		return currency, nil
+	}
+
+	currency, err := cache.repo.GetCurrencyByCode(ctx, code)
+	if err != nil {
+		return models.Currency{}, err
+	}
+	cache.currencies[code] = currency
+
+	return currency, nil
+}
+
+// exchangeRates returns the current rates or, for a non-zero date, the rates
+// that were in effect at that moment, along with their graph. Historical
+// rates are cached by the latest rate change at the date, so dates between
+// the same two changes share one load.
+func (cache *conversionCache) exchangeRates(ctx context.Context, date time.Time) ([]models.CurrencyExchange, rateGraph, error) {
+	key := ""
+	if !date.IsZero() {
+		change, err := cache.repo.GetLatestRateChange(ctx, date)
+		if err != nil {
+			return nil, rateGraph{}, err
+		}
+		key = change.UTC().Format(time.RFC3339)
+	}
+
+	if rates, ok := cache.rates[key]; ok {
+		return rates.exchangerates, rates.graph, nil
+	}
+
+	var (
+		exchangerates []models.CurrencyExchange
+		err           error
+	)
+	if date.IsZero() {
+		exchangerates, err = cache.repo.GetExchangeRates(ctx)
+	} else {
+		exchangerates, err = cache.repo.GetExchangeRatesAt(ctx, date)
+	}
+	if err != nil {
+		return nil, rateGraph{}, err
+	}
+
+	rates := cachedRates{exchangerates: exchangerates, graph: newRateGraph(exchangerates)}
+	cache.rates[key] = rates
+
+	return rates.exchangerates, rates.graph, nil
+}
+
+func (cache *conversionCache) feeRules(ctx context.Context) ([]models.FeeRule, error) {
+	if cache.rules != nil {
+		return cache.rules, nil
+	}
+
+	rules, err := cache.repo.GetFeeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cache.rules = rules
+
+	return rules, nil
+}
@@ -0,0 +1,148 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Import validates every row before anything is written and reports all
+// problems at once. The rows are only stored when none of them is invalid
+// and the request is not a dry run. The inverse policy applies to every rate
+// as if the rows were written one by one, so a pair listed earlier in the
+// file counts as stored.
+func (usecase Usecase) Import(ctx context.Context, request models.ImportRequest) (models.ImportReport, error) {
+	report := models.ImportReport{
+		DryRun:     request.DryRun,
+		Currencies: len(request.Currencies),
+		Rates:      len(request.Rates),
+		Errors:     make([]models.ImportRowError, 0),
+	}
+
+	existing, err := usecase.repo.GetCurrencies(ctx)
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+
+	known := make(map[string]bool, len(existing)+len(request.Currencies))
+	for _, currency := range existing {
+		known[currency.Code] = true
+	}
+
+	currencies := make([]models.Currency, 0, len(request.Currencies))
+	imported := make(map[string]int, len(request.Currencies))
+	for _, row := range request.Currencies {
+		currency := row.Currency
+		if currency.RoundingMode == "" {
+			currency.RoundingMode = models.DefaultRoundingMode
+		}
+
+		var rowErr error
+		switch _, roundingErr := models.ParseRoundingMode(string(currency.RoundingMode)); {
+		case currency.Code == "" || currency.FullName == "" || currency.Sign == "":
+			rowErr = fmt.Errorf("All fields (code, fullname, sign) are required")
+		case currency.MinorUnits < 0 || currency.MinorUnits > models.MaxMinorUnits:
+			rowErr = models.ErrorInvalidMinorUnits
+		case roundingErr != nil:
+			rowErr = roundingErr
+		case imported[currency.Code] != 0:
+			rowErr = fmt.Errorf("Currency %s is already listed on line %d", currency.Code, imported[currency.Code])
+		}
+
+		if rowErr != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{File: models.ImportFileCurrencies, Line: row.Line, Error: rowErr.Error()})
+			continue
+		}
+
+		imported[currency.Code] = row.Line
+		known[currency.Code] = true
+		currencies = append(currencies, currency)
+	}
+
+	type rateKey struct {
+		base, target string
+		effectiveAt  int64
+	}
+	type pairKey struct {
+		base, target string
+	}
+	seen := make(map[rateKey]int, len(request.Rates))
+	listed := make(map[pairKey]decimal.Decimal, len(request.Rates))
+	rates := make([]models.ExchangeRateImportRow, 0, len(request.Rates))
+	now := time.Now()
+	for _, row := range request.Rates {
+		key := rateKey{row.BaseCurrencyCode, row.TargetCurrencyCode, row.EffectiveAt.Unix()}
+
+		var rowErr error
+		switch {
+		case !known[row.BaseCurrencyCode]:
+			rowErr = fmt.Errorf("%w: %s", models.ErrorCurrencyNotFound, row.BaseCurrencyCode)
+		case !known[row.TargetCurrencyCode]:
+			rowErr = fmt.Errorf("%w: %s", models.ErrorCurrencyNotFound, row.TargetCurrencyCode)
+		case row.BaseCurrencyCode == row.TargetCurrencyCode:
+			rowErr = fmt.Errorf("Base and target currency must differ")
+		case !row.Rate.IsPositive():
+			rowErr = fmt.Errorf("Rate must be positive")
+		case row.Spread.Valid && row.EffectiveAt.After(now):
+			rowErr = fmt.Errorf("Spread cannot be scheduled")
+		case row.Spread.Valid && validateSpread(row.Rate, row.Spread.Decimal) != nil:
+			rowErr = models.ErrorInvalidSpread
+		case seen[key] != 0:
+			rowErr = fmt.Errorf("Rate %s%s is already listed on line %d", row.BaseCurrencyCode, row.TargetCurrencyCode, seen[key])
+		}
+
+		var inverse decimal.NullDecimal
+		if rowErr == nil {
+			if inverseRate, ok := listed[pairKey{row.TargetCurrencyCode, row.BaseCurrencyCode}]; ok {
+				inverse, rowErr = usecase.applyInversePolicy(row.BaseCurrencyCode, row.TargetCurrencyCode, row.Rate, inverseRate)
+			} else {
+				inverse, rowErr = usecase.checkInverse(ctx, row.BaseCurrencyCode, row.TargetCurrencyCode, row.Rate)
+			}
+			if rowErr != nil && !errors.Is(rowErr, models.ErrorInverseRateConflict) {
+				return models.ImportReport{}, rowErr
+			}
+		}
+
+		if rowErr != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{File: models.ImportFileRates, Line: row.Line, Error: rowErr.Error()})
+			continue
+		}
+
+		seen[key] = row.Line
+		listed[pairKey{row.BaseCurrencyCode, row.TargetCurrencyCode}] = row.Rate
+		rates = append(rates, row)
+
+		// The inverse pair changes at the same moment.
+		if inverse.Valid {
+			listed[pairKey{row.TargetCurrencyCode, row.BaseCurrencyCode}] = inverse.Decimal
+			rates = append(rates, models.ExchangeRateImportRow{
+				Line:               row.Line,
+				BaseCurrencyCode:   row.TargetCurrencyCode,
+				TargetCurrencyCode: row.BaseCurrencyCode,
+				Rate:               inverse.Decimal,
+				EffectiveAt:        row.EffectiveAt,
+			})
+		}
+	}
+
+	if request.DryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	if err := usecase.repo.Import(ctx, currencies, rates); err != nil {
+		return models.ImportReport{}, err
+	}
+	report.Imported = true
+
+	inconsistencies, err := usecase.CheckExchangeRateWrite(ctx, "", "")
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	report.Inconsistencies = inconsistencies
+
+	return report, nil
+}
@@ -0,0 +1,128 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func (usecase Usecase) CreateFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error) {
+	if err := usecase.validateFeeRule(ctx, rule); err != nil {
+		return models.FeeRule{}, err
+	}
+
+	rule, err := usecase.repo.AddFeeRule(ctx, rule)
+	if err != nil {
+		return models.FeeRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (usecase Usecase) GetFeeRules(ctx context.Context) ([]models.FeeRule, error) {
+	rules, err := usecase.repo.GetFeeRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (usecase Usecase) GetFeeRule(ctx context.Context, id int) (models.FeeRule, error) {
+	rule, err := usecase.repo.GetFeeRule(ctx, id)
+	if err != nil {
+		return models.FeeRule{}, err
+	}
+
+	return rule, nil
+}
+
+func (usecase Usecase) UpdateFeeRule(ctx context.Context, rule models.FeeRule) error {
+	if err := usecase.validateFeeRule(ctx, rule); err != nil {
+		return err
+	}
+
+	if err := usecase.repo.UpdateFeeRule(ctx, rule); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (usecase Usecase) DeleteFeeRule(ctx context.Context, id int) error {
+	if err := usecase.repo.DeleteFeeRule(ctx, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (usecase Usecase) validateFeeRule(ctx context.Context, rule models.FeeRule) error {
+	for _, code := range []string{rule.BaseCurrencyCode, rule.TargetCurrencyCode} {
+		if code == "" {
+			continue
+		}
+		if _, err := usecase.repo.GetCurrencyByCode(ctx, code); err != nil {
+			return err
+		}
+	}
+
+	if rule.MinAmount.IsNegative() || rule.MarkupPercent.IsNegative() || rule.FixedFee.IsNegative() {
+		return models.ErrorInvalidFeeRule
+	}
+
+	if rule.MaxAmount.Valid && !rule.MaxAmount.Decimal.GreaterThan(rule.MinAmount) {
+		return models.ErrorInvalidFeeRule
+	}
+
+	return nil
+}
+
+// matchFeeRule picks the most specific rule matching the conversion. Among
+// equally specific rules the highest amount tier wins, then the oldest rule.
+func matchFeeRule(rules []models.FeeRule, codeBaseCurrency, codeTargetCurrency, clientID string, amount decimal.Decimal) (models.FeeRule, bool) {
+	var (
+		best  models.FeeRule
+		found bool
+	)
+	for _, rule := range rules {
+		if !rule.Matches(codeBaseCurrency, codeTargetCurrency, clientID, amount) {
+			continue
+		}
+
+		if !found || rule.Specificity() > best.Specificity() ||
+			rule.Specificity() == best.Specificity() && rule.MinAmount.GreaterThan(best.MinAmount) {
+			best, found = rule, true
+		}
+	}
+
+	return best, found
+}
+
+// applyFeeRule fills the fee and net amount of a conversion. The fee is added
+// to what the client pays on the buy side and taken from what it receives
+// otherwise.
+func applyFeeRule(result *models.GetExchangeCurrencies, rule models.FeeRule, found bool) error {
+	result.Fee = decimal.Zero
+	result.NetAmount = result.ConvertedAmount
+	if !found {
+		return nil
+	}
+
+	fee := result.ConvertedAmount.Mul(rule.MarkupPercent).Div(decimal.NewFromInt(100)).Add(rule.FixedFee)
+	result.Fee = result.RoundingMode.Round(fee, result.TargetCurrency.MinorUnits)
+	result.FeeRule = &rule
+
+	if result.Side == models.SideBuy {
+		result.NetAmount = result.ConvertedAmount.Add(result.Fee)
+		return nil
+	}
+
+	if result.Fee.GreaterThan(result.ConvertedAmount) {
+		return models.ErrorFeeExceedsAmount
+	}
+	result.NetAmount = result.ConvertedAmount.Sub(result.Fee)
+
+	return nil
+}
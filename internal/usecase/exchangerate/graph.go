@@ -0,0 +1,112 @@
+package exchangerate
+
+import (
+	"currencyservice/internal/models"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// rateGraph treats stored exchange rates as a directed graph of currencies.
+// Every stored BASE→TARGET row is an edge, and unless the reverse pair is
+// stored as well it is also walkable backwards as 1/rate.
+type rateGraph struct {
+	edges map[string]map[string]models.ConversionStep
+}
+
+func newRateGraph(exchangerates []models.CurrencyExchange) rateGraph {
+	graph := rateGraph{edges: make(map[string]map[string]models.ConversionStep)}
+
+	for _, exchangerate := range exchangerates {
+		graph.add(models.ConversionStep{
+			BaseCurrencyCode:   exchangerate.BaseCurrencyCode,
+			TargetCurrencyCode: exchangerate.TargetCurrencyCode,
+			Rate:               exchangerate.Rate,
+			ExchangeRate:       exchangerate,
+		})
+	}
+
+	for _, exchangerate := range exchangerates {
+		if exchangerate.Rate.IsZero() {
+			continue
+		}
+		if _, ok := graph.step(exchangerate.TargetCurrencyCode, exchangerate.BaseCurrencyCode); ok {
+			continue
+		}
+		graph.add(models.ConversionStep{
+			BaseCurrencyCode:   exchangerate.TargetCurrencyCode,
+			TargetCurrencyCode: exchangerate.BaseCurrencyCode,
+			Rate:               decimal.NewFromInt(1).DivRound(exchangerate.Rate, models.RatePrecision),
+			Inverse:            true,
+			ExchangeRate:       exchangerate,
+		})
+	}
+
+	return graph
+}
+
+func (graph rateGraph) add(step models.ConversionStep) {
+	if graph.edges[step.BaseCurrencyCode] == nil {
+		graph.edges[step.BaseCurrencyCode] = make(map[string]models.ConversionStep)
+	}
+	graph.edges[step.BaseCurrencyCode][step.TargetCurrencyCode] = step
+}
+
+func (graph rateGraph) step(codeBaseCurrency, codeTargetCurrency string) (models.ConversionStep, bool) {
+	step, ok := graph.edges[codeBaseCurrency][codeTargetCurrency]
+	return step, ok
+}
+
+// shortestPath finds the path with the fewest hops from BASE to TARGET using
+// breadth-first search, giving up on paths longer than maxDepth. Neighbours
+// are visited in code order so equally short paths resolve deterministically.
+func (graph rateGraph) shortestPath(codeBaseCurrency, codeTargetCurrency string, maxDepth int) ([]models.ConversionStep, bool) {
+	if codeBaseCurrency == codeTargetCurrency || maxDepth <= 0 {
+		return nil, false
+	}
+
+	previous := map[string]models.ConversionStep{}
+	depth := map[string]int{codeBaseCurrency: 0}
+	queue := []string{codeBaseCurrency}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if depth[current] == maxDepth {
+			continue
+		}
+
+		for _, code := range graph.neighbours(current) {
+			if _, seen := depth[code]; seen {
+				continue
+			}
+			depth[code] = depth[current] + 1
+			previous[code] = graph.edges[current][code]
+
+			if code == codeTargetCurrency {
+				path := make([]models.ConversionStep, depth[code])
+				for i := len(path) - 1; i >= 0; i-- {
+					path[i] = previous[code]
+					code = path[i].BaseCurrencyCode
+				}
+				return path, true
+			}
+
+			queue = append(queue, code)
+		}
+	}
+
+	return nil, false
+}
+
+// neighbours returns the currencies reachable in one step, in code order.
+func (graph rateGraph) neighbours(code string) []string {
+	neighbours := make([]string, 0, len(graph.edges[code]))
+	for neighbour := range graph.edges[code] {
+		neighbours = append(neighbours, neighbour)
+	}
+	sort.Strings(neighbours)
+
+	return neighbours
+}
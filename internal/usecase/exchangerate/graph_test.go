@@ -0,0 +1,158 @@
+package exchangerate
+
+import (
+	"currencyservice/internal/models"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func rate(base, target, value string) models.CurrencyExchange {
+	return models.CurrencyExchange{
+		BaseCurrencyCode:   base,
+		TargetCurrencyCode: target,
+		Rate:               decimal.RequireFromString(value),
+	}
+}
+
+// pathCodes renders a path as its currencies joined by arrows, e.g.
+// "USD→EUR→GBP", marking legs walked backwards with a star.
+func pathCodes(path []models.ConversionStep) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	codes := []string{path[0].BaseCurrencyCode}
+	for _, step := range path {
+		code := step.TargetCurrencyCode
+		if step.Inverse {
+			code += "*"
+		}
+		codes = append(codes, code)
+	}
+
+	return strings.Join(codes, "→")
+}
+
+func TestShortestPath(t *testing.T) {
+	graph := newRateGraph([]models.CurrencyExchange{
+		rate("USD", "EUR", "0.9"),
+		rate("EUR", "GBP", "0.85"),
+		rate("GBP", "JPY", "190"),
+		rate("USD", "CHF", "0.88"),
+		rate("CHF", "JPY", "170"),
+		rate("AUD", "NZD", "1.1"),
+	})
+
+	tests := []struct {
+		name     string
+		base     string
+		target   string
+		maxDepth int
+		want     string
+		wantOK   bool
+	}{
+		{name: "single hop", base: "USD", target: "EUR", maxDepth: 4, want: "USD→EUR", wantOK: true},
+		{name: "fewest hops wins", base: "USD", target: "JPY", maxDepth: 4, want: "USD→CHF→JPY", wantOK: true},
+		{name: "walks stored pairs backwards", base: "GBP", target: "USD", maxDepth: 4, want: "GBP→EUR*→USD*", wantOK: true},
+		{name: "mixes directions", base: "EUR", target: "CHF", maxDepth: 4, want: "EUR→USD*→CHF", wantOK: true},
+		{name: "path longer than max depth", base: "EUR", target: "JPY", maxDepth: 1, wantOK: false},
+		{name: "disconnected currencies", base: "USD", target: "NZD", maxDepth: 4, wantOK: false},
+		{name: "unknown currency", base: "USD", target: "XXX", maxDepth: 4, wantOK: false},
+		{name: "same currency", base: "USD", target: "USD", maxDepth: 4, wantOK: false},
+		{name: "disabled", base: "USD", target: "EUR", maxDepth: 0, wantOK: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, ok := graph.shortestPath(test.base, test.target, test.maxDepth)
+			if ok != test.wantOK {
+				t.Fatalf("shortestPath(%s, %s, %d) ok = %v, want %v", test.base, test.target, test.maxDepth, ok, test.wantOK)
+			}
+			if got := pathCodes(path); got != test.want {
+				t.Errorf("shortestPath(%s, %s, %d) = %s, want %s", test.base, test.target, test.maxDepth, got, test.want)
+			}
+		})
+	}
+}
+
+func TestShortestPathResolvesTiesInCodeOrder(t *testing.T) {
+	graph := newRateGraph([]models.CurrencyExchange{
+		rate("USD", "GBP", "0.8"),
+		rate("USD", "EUR", "0.9"),
+		rate("GBP", "JPY", "190"),
+		rate("EUR", "JPY", "170"),
+	})
+
+	for i := 0; i < 10; i++ {
+		path, _ := graph.shortestPath("USD", "JPY", 4)
+		if got, want := pathCodes(path), "USD→EUR→JPY"; got != want {
+			t.Fatalf("shortestPath(USD, JPY) = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestNewRateGraphPrefersStoredReverse(t *testing.T) {
+	graph := newRateGraph([]models.CurrencyExchange{
+		rate("USD", "EUR", "0.9"),
+		rate("EUR", "USD", "1.2"),
+	})
+
+	step, ok := graph.step("EUR", "USD")
+	if !ok {
+		t.Fatal("EUR→USD is not in the graph")
+	}
+	if step.Inverse || !step.Rate.Equal(decimal.RequireFromString("1.2")) {
+		t.Errorf("EUR→USD = %s (inverse %v), want the stored 1.2", step.Rate, step.Inverse)
+	}
+}
+
+func TestFindPath(t *testing.T) {
+	graph := newRateGraph([]models.CurrencyExchange{
+		rate("USD", "EUR", "0.9"),
+		rate("USD", "GBP", "0.8"),
+		rate("GBP", "JPY", "190"),
+		rate("JPY", "KRW", "9"),
+	})
+	usecase := Usecase{config: Config{PivotCurrencyCode: "USD", MaxPathLength: 4}}
+
+	tests := []struct {
+		name       string
+		base       string
+		target     string
+		want       string
+		wantMethod models.ConversionMethod
+	}{
+		{name: "stored pair", base: "USD", target: "EUR", want: "USD→EUR", wantMethod: models.ConversionDirect},
+		{name: "reverse of stored pair", base: "EUR", target: "USD", want: "EUR→USD*", wantMethod: models.ConversionInverse},
+		{name: "cross rate through pivot", base: "EUR", target: "GBP", want: "EUR→USD*→GBP", wantMethod: models.ConversionCross},
+		{name: "multi-hop path", base: "EUR", target: "KRW", want: "EUR→USD*→GBP→JPY→KRW", wantMethod: models.ConversionPath},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, method, err := usecase.findPath(graph, test.base, test.target)
+			if err != nil {
+				t.Fatalf("findPath(%s, %s) error = %v", test.base, test.target, err)
+			}
+			if got := pathCodes(path); got != test.want || method != test.wantMethod {
+				t.Errorf("findPath(%s, %s) = %s (%s), want %s (%s)", test.base, test.target, got, method, test.want, test.wantMethod)
+			}
+		})
+	}
+}
+
+func TestFindPathNotFound(t *testing.T) {
+	graph := newRateGraph([]models.CurrencyExchange{
+		rate("USD", "EUR", "0.9"),
+		rate("EUR", "GBP", "0.85"),
+		rate("GBP", "JPY", "190"),
+	})
+	usecase := Usecase{config: Config{MaxPathLength: 2}}
+
+	if _, _, err := usecase.findPath(graph, "USD", "JPY"); !errors.Is(err, models.ErrorExchangeRateNotFound) {
+		t.Errorf("findPath(USD, JPY) error = %v, want %v", err, models.ErrorExchangeRateNotFound)
+	}
+}
@@ -0,0 +1,131 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"log"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// GetExchangeRateInconsistencies checks every stored rate for cycles whose
+// product deviates from 1 by more than the tolerance. An invalid tolerance
+// falls back to the configured one.
+func (usecase Usecase) GetExchangeRateInconsistencies(ctx context.Context, tolerance decimal.NullDecimal) ([]models.RateInconsistency, error) {
+	if !tolerance.Valid {
+		tolerance = decimal.NewNullDecimal(usecase.config.InconsistencyTolerance)
+	}
+
+	exchangerates, err := usecase.repo.GetExchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return findInconsistencies(newRateGraph(exchangerates), tolerance.Decimal), nil
+}
+
+// CheckExchangeRateWrite returns and logs the inconsistencies involving the
+// pair after it was written, when checks on write are enabled. Empty codes
+// check every pair.
+func (usecase Usecase) CheckExchangeRateWrite(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) ([]models.RateInconsistency, error) {
+	if !usecase.config.CheckInconsistenciesOnWrite {
+		return nil, nil
+	}
+
+	inconsistencies, err := usecase.GetExchangeRateInconsistencies(ctx, decimal.NullDecimal{})
+	if err != nil {
+		return nil, err
+	}
+
+	involved := make([]models.RateInconsistency, 0)
+	for _, inconsistency := range inconsistencies {
+		if codeBaseCurrency != "" && !inCycle(inconsistency.Cycle, codeBaseCurrency, codeTargetCurrency) {
+			continue
+		}
+
+		log.Printf("Inconsistent rates %v: product %s deviates from 1 by %s", inconsistency.Cycle, inconsistency.Product, inconsistency.Deviation)
+		involved = append(involved, inconsistency)
+	}
+
+	return involved, nil
+}
+
+// findInconsistencies checks every pair stored both ways and every triangle
+// of currencies connected by stored pairs.
+func findInconsistencies(graph rateGraph, tolerance decimal.Decimal) []models.RateInconsistency {
+	inconsistencies := make([]models.RateInconsistency, 0)
+
+	codes := make([]string, 0, len(graph.edges))
+	for code := range graph.edges {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, first := range codes {
+		for _, second := range graph.neighbours(first) {
+			if second <= first {
+				continue
+			}
+
+			forward, _ := graph.step(first, second)
+			backward, _ := graph.step(second, first)
+			if !forward.Inverse && !backward.Inverse {
+				if inconsistency, ok := checkCycle([]models.ConversionStep{forward, backward}, tolerance); ok {
+					inconsistencies = append(inconsistencies, inconsistency)
+				}
+			}
+
+			for _, third := range graph.neighbours(second) {
+				if third <= second {
+					continue
+				}
+
+				closing, ok := graph.step(third, first)
+				if !ok {
+					continue
+				}
+
+				middle, _ := graph.step(second, third)
+				if inconsistency, ok := checkCycle([]models.ConversionStep{forward, middle, closing}, tolerance); ok {
+					inconsistencies = append(inconsistencies, inconsistency)
+				}
+			}
+		}
+	}
+
+	return inconsistencies
+}
+
+func checkCycle(steps []models.ConversionStep, tolerance decimal.Decimal) (models.RateInconsistency, bool) {
+	product := pathRate(steps, models.SideMid)
+	cycle := make([]string, 0, len(steps)+1)
+	for _, step := range steps {
+		cycle = append(cycle, step.BaseCurrencyCode)
+	}
+	cycle = append(cycle, steps[0].BaseCurrencyCode)
+
+	deviation := product.Sub(decimal.NewFromInt(1)).Abs()
+	if deviation.LessThanOrEqual(tolerance) {
+		return models.RateInconsistency{}, false
+	}
+
+	return models.RateInconsistency{
+		Cycle:     cycle,
+		Product:   product.Round(models.RatePrecision),
+		Deviation: deviation.Round(models.RatePrecision),
+		Steps:     steps,
+	}, true
+}
+
+// inCycle reports whether the cycle walks the pair in either direction.
+func inCycle(cycle []string, codeBaseCurrency, codeTargetCurrency string) bool {
+	for i := 0; i+1 < len(cycle); i++ {
+		if cycle[i] == codeBaseCurrency && cycle[i+1] == codeTargetCurrency ||
+			cycle[i] == codeTargetCurrency && cycle[i+1] == codeBaseCurrency {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+package exchangerate
+
+import (
+	"currencyservice/internal/models"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestFindInconsistencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		rates     []models.CurrencyExchange
+		tolerance string
+		want      []string
+	}{
+		{
+			name:      "consistent triangle",
+			rates:     []models.CurrencyExchange{rate("USD", "EUR", "0.9"), rate("EUR", "GBP", "0.8"), rate("USD", "GBP", "0.72")},
+			tolerance: "0",
+		},
+		{
+			name:      "inconsistent triangle",
+			rates:     []models.CurrencyExchange{rate("USD", "EUR", "0.9"), rate("EUR", "GBP", "0.8"), rate("USD", "GBP", "0.7")},
+			tolerance: "0.001",
+			want:      []string{"EUR→GBP→USD→EUR 1.0285714285714286"},
+		},
+		{
+			name:      "triangle within tolerance",
+			rates:     []models.CurrencyExchange{rate("USD", "EUR", "0.9"), rate("EUR", "GBP", "0.8"), rate("USD", "GBP", "0.7")},
+			tolerance: "0.03",
+		},
+		{
+			name:      "pair stored both ways",
+			rates:     []models.CurrencyExchange{rate("USD", "EUR", "0.9"), rate("EUR", "USD", "1.2")},
+			tolerance: "0.001",
+			want:      []string{"EUR→USD→EUR 1.08"},
+		},
+		{
+			name:      "reciprocal pair stored both ways",
+			rates:     []models.CurrencyExchange{rate("USD", "JPY", "150"), rate("JPY", "USD", "0.0066666666666667")},
+			tolerance: "0.000001",
+		},
+		{
+			name: "every inconsistent cycle is reported",
+			rates: []models.CurrencyExchange{
+				rate("USD", "EUR", "0.9"), rate("EUR", "USD", "1.2"),
+				rate("EUR", "GBP", "0.8"), rate("GBP", "USD", "1.5"),
+			},
+			tolerance: "0.001",
+			want:      []string{"EUR→GBP→USD→EUR 1.08", "EUR→USD→EUR 1.08"},
+		},
+		{
+			name:      "no cycles",
+			rates:     []models.CurrencyExchange{rate("USD", "EUR", "0.9"), rate("EUR", "GBP", "0.8")},
+			tolerance: "0",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inconsistencies := findInconsistencies(newRateGraph(test.rates), decimal.RequireFromString(test.tolerance))
+
+			got := make([]string, 0, len(inconsistencies))
+			for _, inconsistency := range inconsistencies {
+				got = append(got, strings.Join(inconsistency.Cycle, "→")+" "+inconsistency.Product.String())
+
+				wantDeviation := inconsistency.Product.Sub(decimal.NewFromInt(1)).Abs()
+				if !inconsistency.Deviation.Equal(wantDeviation) {
+					t.Errorf("%v deviation = %s, want %s", inconsistency.Cycle, inconsistency.Deviation, wantDeviation)
+				}
+			}
+			if strings.Join(got, "; ") != strings.Join(test.want, "; ") {
+				t.Errorf("findInconsistencies() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestInCycle(t *testing.T) {
+	cycle := []string{"EUR", "GBP", "USD", "EUR"}
+
+	tests := []struct {
+		base   string
+		target string
+		want   bool
+	}{
+		{base: "EUR", target: "GBP", want: true},
+		{base: "GBP", target: "EUR", want: true},
+		{base: "USD", target: "EUR", want: true},
+		{base: "EUR", target: "USD", want: true},
+		{base: "EUR", target: "JPY", want: false},
+		{base: "EUR", target: "EUR", want: false},
+	}
+
+	for _, test := range tests {
+		if got := inCycle(cycle, test.base, test.target); got != test.want {
+			t.Errorf("inCycle(%v, %s, %s) = %v, want %v", cycle, test.base, test.target, got, test.want)
+		}
+	}
+}
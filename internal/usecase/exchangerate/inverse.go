@@ -0,0 +1,48 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// checkInverse applies the inverse policy to a write of BASE→TARGET when
+// TARGET→BASE is stored. It returns the rate the inverse pair has to be set
+// to, which is invalid when the inverse pair stays as it is.
+func (usecase Usecase) checkInverse(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal) (decimal.NullDecimal, error) {
+	policy := usecase.config.InversePolicy
+	if policy != models.InverseReject && policy != models.InverseMaintain {
+		return decimal.NullDecimal{}, nil
+	}
+
+	inverse, err := usecase.repo.GetExchangeRateByCodesPair(ctx, codeTargetCurrency, codeBaseCurrency)
+	if errors.Is(err, models.ErrorExchangeRateNotFound) {
+		return decimal.NullDecimal{}, nil
+	}
+	if err != nil {
+		return decimal.NullDecimal{}, err
+	}
+
+	return usecase.applyInversePolicy(codeBaseCurrency, codeTargetCurrency, rate, inverse.Rate)
+}
+
+// applyInversePolicy is checkInverse for an inverse pair at inverseRate.
+func (usecase Usecase) applyInversePolicy(codeBaseCurrency, codeTargetCurrency string, rate, inverseRate decimal.Decimal) (decimal.NullDecimal, error) {
+	if usecase.config.InversePolicy == models.InverseMaintain {
+		reciprocal := decimal.NewFromInt(1).DivRound(rate, models.RatePrecision)
+		if inverseRate.Equal(reciprocal) {
+			return decimal.NullDecimal{}, nil
+		}
+		return decimal.NewNullDecimal(reciprocal), nil
+	}
+
+	deviation := rate.Mul(inverseRate).Sub(decimal.NewFromInt(1)).Abs()
+	if deviation.GreaterThan(usecase.config.InconsistencyTolerance) {
+		return decimal.NullDecimal{}, fmt.Errorf("%w: %s%s is %s", models.ErrorInverseRateConflict, codeTargetCurrency, codeBaseCurrency, inverseRate)
+	}
+
+	return decimal.NullDecimal{}, nil
+}
@@ -0,0 +1,66 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// GetExchangeRateMatrix returns the current mid rates among the given
+// currencies, derived the same way as a conversion. No codes means every
+// currency.
+func (usecase Usecase) GetExchangeRateMatrix(ctx context.Context, codes []string) (models.ExchangeRateMatrix, error) {
+	if len(codes) == 0 {
+		currencies, err := usecase.repo.GetCurrencies(ctx)
+		if err != nil {
+			return models.ExchangeRateMatrix{}, err
+		}
+		for _, currency := range currencies {
+			codes = append(codes, currency.Code)
+		}
+	}
+
+	for _, code := range codes {
+		if _, err := usecase.repo.GetCurrencyByCode(ctx, code); err != nil {
+			return models.ExchangeRateMatrix{}, err
+		}
+	}
+
+	exchangerates, err := usecase.repo.GetExchangeRates(ctx)
+	if err != nil {
+		return models.ExchangeRateMatrix{}, err
+	}
+	graph := newRateGraph(exchangerates)
+
+	matrix := models.ExchangeRateMatrix{
+		Currencies: codes,
+		Rates:      make([][]models.ExchangeRateMatrixCell, len(codes)),
+	}
+	for i, base := range codes {
+		matrix.Rates[i] = make([]models.ExchangeRateMatrixCell, len(codes))
+		for j, target := range codes {
+			if base == target {
+				matrix.Rates[i][j] = models.ExchangeRateMatrixCell{
+					Rate:   decimal.NewNullDecimal(decimal.NewFromInt(1)),
+					Method: models.ConversionIdentity,
+				}
+				continue
+			}
+
+			path, method, err := usecase.findPath(graph, base, target)
+			if errors.Is(err, models.ErrorExchangeRateNotFound) {
+				continue
+			}
+			if err != nil {
+				return models.ExchangeRateMatrix{}, err
+			}
+
+			rate := pathRate(path, models.SideMid)
+			matrix.Rates[i][j] = models.ExchangeRateMatrixCell{Rate: decimal.NewNullDecimal(rate), Method: method}
+		}
+	}
+
+	return matrix, nil
+}
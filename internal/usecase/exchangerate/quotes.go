@@ -0,0 +1,58 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"time"
+)
+
+// CreateQuote converts at the current rates and locks the result for the
+// configured quote TTL.
+func (usecase Usecase) CreateQuote(ctx context.Context, request models.ExchangeRequest) (models.Quote, error) {
+	request.Date = time.Time{}
+
+	result, err := usecase.GetExchangeCurrencies(ctx, request)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	now := time.Now()
+	quote, err := usecase.repo.AddQuote(ctx, models.Quote{
+		BaseCurrencyCode:   result.BaseCurrency.Code,
+		TargetCurrencyCode: result.TargetCurrency.Code,
+		Amount:             result.Amount,
+		Rate:               result.Rate,
+		ConvertedAmount:    result.ConvertedAmount,
+		Fee:                result.Fee,
+		NetAmount:          result.NetAmount,
+		Side:               result.Side,
+		ClientID:           request.ClientID,
+		CreatedAt:          now,
+		ExpiresAt:          now.Add(usecase.config.QuoteTTL),
+	})
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	return quote, nil
+}
+
+func (usecase Usecase) GetQuote(ctx context.Context, id int) (models.Quote, error) {
+	quote, err := usecase.repo.GetQuote(ctx, id)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	return quote, nil
+}
+
+// ExecuteQuote confirms the conversion at the locked rate, unless the quote
+// has expired or was executed already.
+func (usecase Usecase) ExecuteQuote(ctx context.Context, id int) (models.Quote, error) {
+	quote, err := usecase.repo.ExecuteQuote(ctx, id, time.Now())
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	return quote, nil
+}
@@ -0,0 +1,47 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Repository is the storage the usecase works on. It is implemented by the
+// SQLite backed currencies.Repo, the Postgres backed postgres.Repo and the
+// in-memory memory.Repo.
+type Repository interface {
+	AddCurrency(ctx context.Context, currency models.Currency) error
+	GetCurrencyByCode(ctx context.Context, code string) (models.Currency, error)
+	GetCurrencies(ctx context.Context) ([]models.Currency, error)
+
+	GetExchangeRates(ctx context.Context) ([]models.CurrencyExchange, error)
+	GetExchangeRateByCodesPair(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.CurrencyExchange, error)
+	AddExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate, spread decimal.Decimal, inverse decimal.NullDecimal) error
+	UpdateExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, newRate decimal.Decimal, spread, inverse decimal.NullDecimal) error
+	TouchExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, at time.Time) error
+
+	GetExchangeRatesAt(ctx context.Context, at time.Time) ([]models.CurrencyExchange, error)
+	GetLatestRateChange(ctx context.Context, at time.Time) (time.Time, error)
+	GetExchangeRateHistory(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) ([]models.HistoricalRate, error)
+	GetExchangeRateOHLC(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time, interval time.Duration) ([]models.RateCandle, error)
+	GetExchangeRateStatistics(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) (models.RateStatistics, error)
+
+	AddScheduledExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveAt time.Time) (models.ScheduledExchangeRate, error)
+	GetScheduledExchangeRates(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) ([]models.ScheduledExchangeRate, error)
+	CancelScheduledExchangeRate(ctx context.Context, id int) error
+	ActivateScheduledExchangeRates(ctx context.Context, now time.Time) (int, error)
+
+	Import(ctx context.Context, currencies []models.Currency, rates []models.ExchangeRateImportRow) error
+
+	AddFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error)
+	GetFeeRules(ctx context.Context) ([]models.FeeRule, error)
+	GetFeeRule(ctx context.Context, id int) (models.FeeRule, error)
+	UpdateFeeRule(ctx context.Context, rule models.FeeRule) error
+	DeleteFeeRule(ctx context.Context, id int) error
+
+	AddQuote(ctx context.Context, quote models.Quote) (models.Quote, error)
+	GetQuote(ctx context.Context, id int) (models.Quote, error)
+	ExecuteQuote(ctx context.Context, id int, at time.Time) (models.Quote, error)
+}
@@ -0,0 +1,119 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ScheduleNewExchangeRate stores the rate of a pair that does not exist yet;
+// the pair is created once effectiveAt has passed.
+func (usecase Usecase) ScheduleNewExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveAt time.Time) (models.ScheduledExchangeRate, error) {
+	_, err := usecase.repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency)
+	if err == nil {
+		return models.ScheduledExchangeRate{}, models.ErrorExchangeRateAlreadyExists
+	}
+	if !errors.Is(err, models.ErrorExchangeRateNotFound) {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	return usecase.scheduleExchangeRate(ctx, codeBaseCurrency, codeTargetCurrency, rate, effectiveAt)
+}
+
+// ScheduleExchangeRateUpdate stores a new rate for an existing pair that
+// replaces the current one once effectiveAt has passed.
+func (usecase Usecase) ScheduleExchangeRateUpdate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, newRate decimal.Decimal, effectiveAt time.Time) (models.ScheduledExchangeRate, error) {
+	if _, err := usecase.repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency); err != nil {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	return usecase.scheduleExchangeRate(ctx, codeBaseCurrency, codeTargetCurrency, newRate, effectiveAt)
+}
+
+func (usecase Usecase) scheduleExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate decimal.Decimal, effectiveAt time.Time) (models.ScheduledExchangeRate, error) {
+	inverse, err := usecase.checkInverse(ctx, codeBaseCurrency, codeTargetCurrency, rate)
+	if err != nil {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	scheduled, err := usecase.repo.AddScheduledExchangeRate(ctx, codeBaseCurrency, codeTargetCurrency, rate, effectiveAt)
+	if err != nil {
+		return models.ScheduledExchangeRate{}, err
+	}
+
+	// The inverse pair changes at the same moment.
+	if inverse.Valid {
+		if _, err := usecase.repo.AddScheduledExchangeRate(ctx, codeTargetCurrency, codeBaseCurrency, inverse.Decimal, effectiveAt); err != nil {
+			return models.ScheduledExchangeRate{}, err
+		}
+	}
+
+	// A rate effective in the past is applied right away.
+	if !scheduled.EffectiveAt.After(time.Now()) {
+		if err := usecase.activateScheduledRates(ctx); err != nil {
+			return models.ScheduledExchangeRate{}, err
+		}
+		scheduled.Status = models.ScheduledRateActive
+	}
+
+	return scheduled, nil
+}
+
+// GetScheduledExchangeRates lists pending rates, optionally of a single pair.
+func (usecase Usecase) GetScheduledExchangeRates(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) ([]models.ScheduledExchangeRate, error) {
+	scheduledRates, err := usecase.repo.GetScheduledExchangeRates(ctx, codeBaseCurrency, codeTargetCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduledRates, nil
+}
+
+// CancelScheduledExchangeRate cancels a pending rate. Rates that became due
+// are applied first, so a rate already in effect cannot be cancelled.
+func (usecase Usecase) CancelScheduledExchangeRate(ctx context.Context, id int) error {
+	if err := usecase.activateScheduledRates(ctx); err != nil {
+		return err
+	}
+
+	if err := usecase.repo.CancelScheduledExchangeRate(ctx, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// StartScheduledActivation applies scheduled rates that became due every
+// interval until ctx is done. Reads do not apply them, so a scheduled rate
+// becomes the current one up to interval after its effective time; its
+// history starts at the effective time all the same.
+func (usecase Usecase) StartScheduledActivation(ctx context.Context, interval time.Duration) {
+	go usecase.activateEvery(ctx, interval)
+}
+
+func (usecase Usecase) activateEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := usecase.activateScheduledRates(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to activate scheduled rates: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// activateScheduledRates applies scheduled rates that became due.
+func (usecase Usecase) activateScheduledRates(ctx context.Context) error {
+	_, err := usecase.repo.ActivateScheduledExchangeRates(ctx, time.Now())
+	return err
+}
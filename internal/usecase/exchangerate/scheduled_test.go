@@ -0,0 +1,66 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"currencyservice/internal/repo/memory"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestStartScheduledActivation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := memory.NewRepo()
+	for _, code := range []string{"USD", "EUR"} {
+		currency := models.Currency{Code: code, FullName: code, Sign: code, MinorUnits: 2, RoundingMode: models.RoundingHalfUp}
+		if err := repo.AddCurrency(ctx, currency); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.AddExchangeRate(ctx, "USD", "EUR", decimal.RequireFromString("0.9"), decimal.Zero, decimal.NullDecimal{}); err != nil {
+		t.Fatal(err)
+	}
+
+	usecase := NewUsecase(repo, Config{InversePolicy: models.InverseAllow})
+	effectiveAt := time.Now().Truncate(time.Second).Add(time.Second)
+	if _, err := usecase.ScheduleExchangeRateUpdate(ctx, "USD", "EUR", decimal.RequireFromString("0.95"), effectiveAt); err != nil {
+		t.Fatal(err)
+	}
+
+	current := func() decimal.Decimal {
+		t.Helper()
+
+		rate, err := usecase.GetExchangeRateByCodesPair(ctx, "USD", "EUR")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rate.Rate
+	}
+
+	// Reads leave the due rate to the background activation.
+	time.Sleep(time.Until(effectiveAt) + 100*time.Millisecond)
+	if got := current(); !got.Equal(decimal.RequireFromString("0.9")) {
+		t.Fatalf("USD→EUR before the activation started = %s, want 0.9", got)
+	}
+
+	usecase.StartScheduledActivation(ctx, 10*time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for !current().Equal(decimal.RequireFromString("0.95")) {
+		if time.Now().After(deadline) {
+			t.Fatalf("USD→EUR = %s after the activation started, want 0.95", current())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	history, err := usecase.GetExchangeRateHistory(ctx, "USD", "EUR", effectiveAt, effectiveAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || !history[0].Rate.Equal(decimal.RequireFromString("0.95")) {
+		t.Errorf("history at %s = %+v, want the scheduled rate effective then", effectiveAt, history)
+	}
+}
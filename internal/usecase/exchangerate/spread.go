@@ -0,0 +1,65 @@
+package exchangerate
+
+import (
+	"currencyservice/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// validateSpread requires a non-negative spread that keeps the bid positive.
+func validateSpread(rate, spread decimal.Decimal) error {
+	if spread.IsNegative() || !rate.Sub(spread.Div(decimal.NewFromInt(2))).IsPositive() {
+		return models.ErrorInvalidSpread
+	}
+
+	return nil
+}
+
+// sideRate returns the rate of the step for the given side.
+func sideRate(step models.ConversionStep, side models.Side) decimal.Decimal {
+	rate := storedSideRate(step, side)
+	if step.Inverse {
+		return decimal.NewFromInt(1).DivRound(rate, models.RatePrecision)
+	}
+
+	return rate
+}
+
+// storedSideRate returns the rate of the stored pair the step walks. Walking
+// a stored pair backwards swaps its sides: selling the step's base means
+// buying the stored pair's base at the ask, so the inverse bid is 1/ask and
+// vice versa.
+func storedSideRate(step models.ConversionStep, side models.Side) decimal.Decimal {
+	exchangerate := step.ExchangeRate
+
+	switch {
+	case side == models.SideSell && !step.Inverse, side == models.SideBuy && step.Inverse:
+		return exchangerate.Bid()
+	case side == models.SideBuy && !step.Inverse, side == models.SideSell && step.Inverse:
+		return exchangerate.Ask()
+	default:
+		return exchangerate.Rate
+	}
+}
+
+// pathRate returns the rate of a conversion along the path. Inverse legs
+// divide by the stored rate instead of multiplying by its rounded reciprocal,
+// and that division happens once at the end, so the rate is rounded once no
+// matter how many legs the path has.
+func pathRate(path []models.ConversionStep, side models.Side) decimal.Decimal {
+	numerator := decimal.NewFromInt(1)
+	denominator := decimal.NewFromInt(1)
+	for _, step := range path {
+		if step.Inverse {
+			denominator = denominator.Mul(storedSideRate(step, side))
+		} else {
+			numerator = numerator.Mul(storedSideRate(step, side))
+		}
+	}
+
+	if denominator.Equal(decimal.NewFromInt(1)) {
+		return numerator
+	}
+
+	return numerator.DivRound(denominator, models.RatePrecision)
+}
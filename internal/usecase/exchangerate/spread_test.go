@@ -0,0 +1,162 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"currencyservice/internal/repo/memory"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func spreadRate(base, target, value, spread string) models.CurrencyExchange {
+	exchangerate := rate(base, target, value)
+	exchangerate.Spread = decimal.RequireFromString(spread)
+
+	return exchangerate
+}
+
+func TestPathRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		rates  []models.CurrencyExchange
+		base   string
+		target string
+		side   models.Side
+		want   string
+	}{
+		{
+			name:   "forward legs multiply exactly",
+			rates:  []models.CurrencyExchange{rate("USD", "EUR", "0.1"), rate("EUR", "GBP", "0.2")},
+			base:   "USD",
+			target: "GBP",
+			side:   models.SideMid,
+			want:   "0.02",
+		},
+		{
+			name:   "inverse leg divides",
+			rates:  []models.CurrencyExchange{rate("EUR", "JPY", "166.6666666666666667")},
+			base:   "JPY",
+			target: "EUR",
+			side:   models.SideMid,
+			want:   "0.006",
+		},
+		{
+			name:   "inverse legs round once",
+			rates:  []models.CurrencyExchange{rate("EUR", "USD", "3"), rate("USD", "GBP", "3"), rate("GBP", "JPY", "3")},
+			base:   "JPY",
+			target: "EUR",
+			side:   models.SideMid,
+			want:   "0.037037037037037",
+		},
+		{
+			name:   "sell forward uses the bid",
+			rates:  []models.CurrencyExchange{spreadRate("USD", "EUR", "0.9", "0.02")},
+			base:   "USD",
+			target: "EUR",
+			side:   models.SideSell,
+			want:   "0.89",
+		},
+		{
+			name:   "sell inverse divides by the ask",
+			rates:  []models.CurrencyExchange{spreadRate("EUR", "USD", "1.25", "0.5")},
+			base:   "USD",
+			target: "EUR",
+			side:   models.SideSell,
+			want:   "0.6666666666666667",
+		},
+		{
+			name:   "buy inverse divides by the bid",
+			rates:  []models.CurrencyExchange{spreadRate("EUR", "USD", "1.25", "0.5")},
+			base:   "USD",
+			target: "EUR",
+			side:   models.SideBuy,
+			want:   "1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, ok := newRateGraph(test.rates).shortestPath(test.base, test.target, len(test.rates))
+			if !ok {
+				t.Fatalf("no path from %s to %s", test.base, test.target)
+			}
+			if got := pathRate(path, test.side); !got.Equal(decimal.RequireFromString(test.want)) {
+				t.Errorf("pathRate(%s) = %s, want %s", pathCodes(path), got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateSpread(t *testing.T) {
+	tests := []struct {
+		rate    string
+		spread  string
+		wantErr bool
+	}{
+		{rate: "1.1", spread: "0", wantErr: false},
+		{rate: "1.1", spread: "0.02", wantErr: false},
+		{rate: "1.1", spread: "2.1999", wantErr: false},
+		{rate: "1.1", spread: "2.2", wantErr: true},
+		{rate: "1.1", spread: "-0.01", wantErr: true},
+	}
+
+	for _, test := range tests {
+		err := validateSpread(decimal.RequireFromString(test.rate), decimal.RequireFromString(test.spread))
+		if (err != nil) != test.wantErr {
+			t.Errorf("validateSpread(%s, %s) error = %v, want error %v", test.rate, test.spread, err, test.wantErr)
+		}
+	}
+}
+
+func TestGetExchangeCurrenciesRoundsOnlyTheResult(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepo()
+	for _, currency := range []models.Currency{
+		{Code: "USD", FullName: "US Dollar", Sign: "$", MinorUnits: 2, RoundingMode: models.RoundingHalfUp},
+		{Code: "EUR", FullName: "Euro", Sign: "€", MinorUnits: 2, RoundingMode: models.RoundingHalfEven},
+		{Code: "JPY", FullName: "Yen", Sign: "¥", MinorUnits: 0, RoundingMode: models.RoundingHalfUp},
+	} {
+		if err := repo.AddCurrency(ctx, currency); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, exchangerate := range []models.CurrencyExchange{rate("USD", "EUR", "0.3"), rate("EUR", "JPY", "150")} {
+		err := repo.AddExchangeRate(ctx, exchangerate.BaseCurrencyCode, exchangerate.TargetCurrencyCode, exchangerate.Rate, decimal.Zero, decimal.NullDecimal{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	usecase := NewUsecase(repo, Config{MaxPathLength: 4, InversePolicy: models.InverseAllow})
+
+	tests := []struct {
+		base          string
+		target        string
+		amount        string
+		wantRate      string
+		wantConverted string
+		wantLegs      int
+	}{
+		{base: "USD", target: "EUR", amount: "0.1", wantRate: "0.3", wantConverted: "0.03", wantLegs: 1},
+		{base: "USD", target: "EUR", amount: "0.05", wantRate: "0.3", wantConverted: "0.02", wantLegs: 1},
+		{base: "USD", target: "JPY", amount: "10.01", wantRate: "45", wantConverted: "450", wantLegs: 2},
+		{base: "JPY", target: "USD", amount: "1000", wantRate: "0.0222222222222222", wantConverted: "22.22", wantLegs: 2},
+	}
+
+	for _, test := range tests {
+		result, err := usecase.GetExchangeCurrencies(ctx, models.ExchangeRequest{
+			BaseCurrencyCode:   test.base,
+			TargetCurrencyCode: test.target,
+			Amount:             decimal.RequireFromString(test.amount),
+		})
+		if err != nil {
+			t.Fatalf("%s %s→%s: %v", test.amount, test.base, test.target, err)
+		}
+		if !result.Rate.Equal(decimal.RequireFromString(test.wantRate)) || !result.ConvertedAmount.Equal(decimal.RequireFromString(test.wantConverted)) {
+			t.Errorf("%s %s→%s = %s at %s, want %s at %s", test.amount, test.base, test.target, result.ConvertedAmount, result.Rate, test.wantConverted, test.wantRate)
+		}
+		if len(result.Path) != test.wantLegs {
+			t.Errorf("%s→%s took %d legs, want %d", test.base, test.target, len(result.Path), test.wantLegs)
+		}
+	}
+}
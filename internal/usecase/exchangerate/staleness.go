@@ -0,0 +1,64 @@
+package exchangerate
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"fmt"
+	"time"
+)
+
+// maxRateAge returns the maximum age configured for the stored pair, falling
+// back to the global one. Zero means the pair never goes stale.
+func (usecase Usecase) maxRateAge(codeBaseCurrency, codeTargetCurrency string) time.Duration {
+	if maxAge, ok := usecase.config.PairMaxRateAge[codeBaseCurrency+codeTargetCurrency]; ok {
+		return maxAge
+	}
+
+	return usecase.config.MaxRateAge
+}
+
+func (usecase Usecase) isStale(exchangerate models.CurrencyExchange, at time.Time) bool {
+	maxAge := usecase.maxRateAge(exchangerate.BaseCurrencyCode, exchangerate.TargetCurrencyCode)
+	return maxAge > 0 && at.Sub(exchangerate.UpdatedAt) > maxAge
+}
+
+// staleRates returns a description of every stored pair the path relies on
+// that was stale at the given moment.
+func (usecase Usecase) staleRates(path []models.ConversionStep, at time.Time) []string {
+	stale := make([]string, 0)
+	for _, step := range path {
+		exchangerate := step.ExchangeRate
+		if !usecase.isStale(exchangerate, at) {
+			continue
+		}
+
+		stale = append(stale, fmt.Sprintf("%s%s rate last updated at %s is older than %s",
+			exchangerate.BaseCurrencyCode, exchangerate.TargetCurrencyCode,
+			exchangerate.UpdatedAt.Format(time.RFC3339),
+			usecase.maxRateAge(exchangerate.BaseCurrencyCode, exchangerate.TargetCurrencyCode)))
+	}
+
+	return stale
+}
+
+// freshRates drops the rates that were stale at the given moment.
+func (usecase Usecase) freshRates(exchangerates []models.CurrencyExchange, at time.Time) []models.CurrencyExchange {
+	fresh := make([]models.CurrencyExchange, 0, len(exchangerates))
+	for _, exchangerate := range exchangerates {
+		if !usecase.isStale(exchangerate, at) {
+			fresh = append(fresh, exchangerate)
+		}
+	}
+
+	return fresh
+}
+
+// ConfirmExchangeRate records that a source still reports the current value of
+// the pair, so an unchanged rate does not go stale.
+func (usecase Usecase) ConfirmExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) error {
+	if err := usecase.repo.TouchExchangeRate(ctx, codeBaseCurrency, codeTargetCurrency, time.Now()); err != nil {
+		return err
+	}
+
+	return nil
+}
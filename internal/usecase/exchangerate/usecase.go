@@ -1,21 +1,52 @@
 package exchangerate
 
 import (
+	"context"
 	"currencyservice/internal/models"
-	"currencyservice/internal/repo/currencies"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+type Config struct {
+	// PivotCurrencyCode is used to derive cross rates (BASE→PIVOT→TARGET)
+	// when neither the direct nor the reverse pair is stored. Empty disables it.
+	PivotCurrencyCode string
+	// MaxPathLength limits the number of hops of a multi-hop conversion
+	// path through the rate graph. Zero disables multi-hop conversion.
+	MaxPathLength int
+	// MaxRateAge is how long a stored rate stays fresh after its last update.
+	// PairMaxRateAge overrides it for pairs keyed like "USDEUR". Zero
+	// disables staleness checks.
+	MaxRateAge     time.Duration
+	PairMaxRateAge map[string]time.Duration
+	// StalenessPolicy decides what a conversion relying on a stale rate does.
+	StalenessPolicy models.StalenessPolicy
+	// QuoteTTL is how long a quote can be executed at its locked rate.
+	QuoteTTL time.Duration
+	// InconsistencyTolerance is how far the product of a cycle of rates may
+	// deviate from 1. CheckInconsistenciesOnWrite runs the check after writes.
+	InconsistencyTolerance      decimal.Decimal
+	CheckInconsistenciesOnWrite bool
+	// InversePolicy decides what a write does when the inverse pair is stored.
+	// Contradicting means deviating by more than InconsistencyTolerance.
+	InversePolicy models.InversePolicy
+}
+
 type Usecase struct {
-	repo *currencies.Repo
+	repo   Repository
+	config Config
 }
 
-func NewUsecase(repo *currencies.Repo) *Usecase {
-	return &Usecase{repo: repo}
+func NewUsecase(repo Repository, config Config) *Usecase {
+	return &Usecase{repo: repo, config: config}
 }
 
-func (usecase Usecase) GetCurrency(code string) (models.Currency, error) {
-	currency, err := usecase.repo.GetCurrencyByCode(code)
+func (usecase Usecase) GetCurrency(ctx context.Context, code string) (models.Currency, error) {
+	currency, err := usecase.repo.GetCurrencyByCode(ctx, code)
 	if err != nil {
 		return models.Currency{}, err
 	}
@@ -23,8 +54,8 @@ func (usecase Usecase) GetCurrency(code string) (models.Currency, error) {
 	return currency, nil
 }
 
-func (usecase Usecase) GetAllCurrencies() ([]models.Currency, error) {
-	currencies, err := usecase.repo.GetCurrencies()
+func (usecase Usecase) GetAllCurrencies(ctx context.Context) ([]models.Currency, error) {
+	currencies, err := usecase.repo.GetCurrencies(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -32,41 +63,55 @@ func (usecase Usecase) GetAllCurrencies() ([]models.Currency, error) {
 	return currencies, nil
 }
 
-func (usecase Usecase) CreateNewCurrency(code, fullname, sign string) error {
-	if err := usecase.repo.AddCurrency(code, fullname, sign); err != nil {
+func (usecase Usecase) CreateNewCurrency(ctx context.Context, currency models.Currency) error {
+	if currency.MinorUnits < 0 || currency.MinorUnits > models.MaxMinorUnits {
+		return models.ErrorInvalidMinorUnits
+	}
+
+	if currency.RoundingMode == "" {
+		currency.RoundingMode = models.DefaultRoundingMode
+	}
+
+	if _, err := models.ParseRoundingMode(string(currency.RoundingMode)); err != nil {
+		return err
+	}
+
+	if err := usecase.repo.AddCurrency(ctx, currency); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (usecase Usecase) CreateExchangeRate(codeBaseCurrency, codeTargetCurrency string, rate float64) error {
-	if _, err := usecase.repo.GetCurrencyByCode(codeBaseCurrency); err != nil {
+func (usecase Usecase) CreateExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate, spread decimal.Decimal) error {
+	if err := validateSpread(rate, spread); err != nil {
 		return err
 	}
 
-	if _, err := usecase.repo.GetCurrencyByCode(codeTargetCurrency); err != nil {
+	if _, err := usecase.repo.GetCurrencyByCode(ctx, codeBaseCurrency); err != nil {
 		return err
 	}
 
-	exchangerate, err := usecase.repo.GetExchangeRateByCodesPair(codeBaseCurrency, codeTargetCurrency)
-	if err != nil && !errors.Is(err, models.ErrorExchangeRateNotFound) {
+	if _, err := usecase.repo.GetCurrencyByCode(ctx, codeTargetCurrency); err != nil {
 		return err
 	}
 
-	if exchangerate.ID != 0 {
-		return models.ErrorExchangeRateAlreadyExists
+	inverse, err := usecase.checkInverse(ctx, codeBaseCurrency, codeTargetCurrency, rate)
+	if err != nil {
+		return err
 	}
 
-	if err := usecase.repo.AddExchangeRate(codeBaseCurrency, codeTargetCurrency, rate); err != nil {
+	// The repository reports ErrorExchangeRateAlreadyExists for a stored pair,
+	// so concurrent creations cannot both succeed.
+	if err := usecase.repo.AddExchangeRate(ctx, codeBaseCurrency, codeTargetCurrency, rate, spread, inverse); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (usecase Usecase) GetExchangeRates() ([]models.CurrencyExchange, error) {
-	exchangerates, err := usecase.repo.GetExchangeRates()
+func (usecase Usecase) GetExchangeRates(ctx context.Context) ([]models.CurrencyExchange, error) {
+	exchangerates, err := usecase.repo.GetExchangeRates(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -74,8 +119,8 @@ func (usecase Usecase) GetExchangeRates() ([]models.CurrencyExchange, error) {
 	return exchangerates, nil
 }
 
-func (usecase Usecase) GetExchangeRateByCodesPair(codeBaseCurrency, codeTargetCurrency string) (models.CurrencyExchange, error) {
-	exchangerate, err := usecase.repo.GetExchangeRateByCodesPair(codeBaseCurrency, codeTargetCurrency)
+func (usecase Usecase) GetExchangeRateByCodesPair(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.CurrencyExchange, error) {
+	exchangerate, err := usecase.repo.GetExchangeRateByCodesPair(ctx, codeBaseCurrency, codeTargetCurrency)
 	if err != nil {
 		return models.CurrencyExchange{}, err
 	}
@@ -83,44 +128,199 @@ func (usecase Usecase) GetExchangeRateByCodesPair(codeBaseCurrency, codeTargetCu
 	return exchangerate, nil
 }
 
-func (usecase Usecase) AddExchangeRate(codeBaseCurrency, codeTargetCurrency string, rate float64) error {
-	if err := usecase.repo.AddExchangeRate(codeBaseCurrency, codeTargetCurrency, rate); err != nil {
+func (usecase Usecase) AddExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, rate, spread decimal.Decimal) error {
+	if err := validateSpread(rate, spread); err != nil {
+		return err
+	}
+
+	inverse, err := usecase.checkInverse(ctx, codeBaseCurrency, codeTargetCurrency, rate)
+	if err != nil {
+		return err
+	}
+
+	if err := usecase.repo.AddExchangeRate(ctx, codeBaseCurrency, codeTargetCurrency, rate, spread, inverse); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (usecase Usecase) UpdateExchangeRate(codeBaseCurrency, codeTargetCurrency string, newRate float64) error {
-	if err := usecase.repo.UpdateExchangeRate(codeBaseCurrency, codeTargetCurrency, newRate); err != nil {
+// UpdateExchangeRate sets a new mid rate. An invalid spread keeps the spread
+// the pair already has.
+func (usecase Usecase) UpdateExchangeRate(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, newRate decimal.Decimal, spread decimal.NullDecimal) error {
+	if spread.Valid {
+		if err := validateSpread(newRate, spread.Decimal); err != nil {
+			return err
+		}
+	}
+
+	inverse, err := usecase.checkInverse(ctx, codeBaseCurrency, codeTargetCurrency, newRate)
+	if err != nil {
+		return err
+	}
+
+	if err := usecase.repo.UpdateExchangeRate(ctx, codeBaseCurrency, codeTargetCurrency, newRate, spread, inverse); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-// GET /exchange?from=BASE_CURRENCY_CODE&to=TARGET_CURRENCY_CODE&amount=$AMOUNT
-func (usecase Usecase) GetExchangeCurrencies(codeBaseCurrency, codeTargetCurrency string, amount float64) (models.GetExchangeCurrencies, error) {
-	baseCurrency, err := usecase.repo.GetCurrencyByCode(codeBaseCurrency)
+// findPath resolves BASE→TARGET in order of preference: the stored pair,
+// its reverse, a cross rate through the pivot currency and finally the
+// shortest multi-hop path through the rate graph.
+func (usecase Usecase) findPath(graph rateGraph, codeBaseCurrency, codeTargetCurrency string) ([]models.ConversionStep, models.ConversionMethod, error) {
+	if step, ok := graph.step(codeBaseCurrency, codeTargetCurrency); ok {
+		if step.Inverse {
+			return []models.ConversionStep{step}, models.ConversionInverse, nil
+		}
+		return []models.ConversionStep{step}, models.ConversionDirect, nil
+	}
+
+	pivot := usecase.config.PivotCurrencyCode
+	if pivot != "" && pivot != codeBaseCurrency && pivot != codeTargetCurrency {
+		toPivot, okToPivot := graph.step(codeBaseCurrency, pivot)
+		fromPivot, okFromPivot := graph.step(pivot, codeTargetCurrency)
+		if okToPivot && okFromPivot {
+			return []models.ConversionStep{toPivot, fromPivot}, models.ConversionCross, nil
+		}
+	}
+
+	if path, ok := graph.shortestPath(codeBaseCurrency, codeTargetCurrency, usecase.config.MaxPathLength); ok {
+		return path, models.ConversionPath, nil
+	}
+
+	return nil, "", models.ErrorExchangeRateNotFound
+}
+
+// GetExchangeRateHistory returns the values the pair had between from and to.
+func (usecase Usecase) GetExchangeRateHistory(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time) ([]models.HistoricalRate, error) {
+	history, err := usecase.repo.GetExchangeRateHistory(ctx, codeBaseCurrency, codeTargetCurrency, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// GetExchangeRateOHLC returns the pair's history between from and to bucketed
+// into candles of the given interval along with statistics for the whole range.
+func (usecase Usecase) GetExchangeRateOHLC(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, from, to time.Time, interval time.Duration) (models.ExchangeRateOHLC, error) {
+	if interval < time.Second {
+		return models.ExchangeRateOHLC{}, models.ErrorInvalidInterval
+	}
+
+	candles, err := usecase.repo.GetExchangeRateOHLC(ctx, codeBaseCurrency, codeTargetCurrency, from, to, interval)
+	if err != nil {
+		return models.ExchangeRateOHLC{}, err
+	}
+
+	statistics, err := usecase.repo.GetExchangeRateStatistics(ctx, codeBaseCurrency, codeTargetCurrency, from, to)
+	if err != nil {
+		return models.ExchangeRateOHLC{}, err
+	}
+
+	return models.ExchangeRateOHLC{
+		BaseCurrencyCode:   codeBaseCurrency,
+		TargetCurrencyCode: codeTargetCurrency,
+		Interval:           interval.String(),
+		Candles:            candles,
+		Statistics:         statistics,
+	}, nil
+}
+
+// GET /exchange?from=BASE_CURRENCY_CODE&to=TARGET_CURRENCY_CODE&amount=$AMOUNT[&date=DATE]
+func (usecase Usecase) GetExchangeCurrencies(ctx context.Context, request models.ExchangeRequest) (models.GetExchangeCurrencies, error) {
+	return usecase.convert(ctx, newConversionCache(usecase.repo), request)
+}
+
+func (usecase Usecase) convert(ctx context.Context, cache *conversionCache, request models.ExchangeRequest) (models.GetExchangeCurrencies, error) {
+	baseCurrency, err := cache.currency(ctx, request.BaseCurrencyCode)
 	if err != nil {
 		return models.GetExchangeCurrencies{}, err
 	}
 
-	targetCurrency, err := usecase.repo.GetCurrencyByCode(codeTargetCurrency)
+	targetCurrency, err := cache.currency(ctx, request.TargetCurrencyCode)
 	if err != nil {
 		return models.GetExchangeCurrencies{}, err
 	}
 
-	exchangerate, err := usecase.GetExchangeRateByCodesPair(codeBaseCurrency, codeTargetCurrency)
+	exchangerates, graph, err := cache.exchangeRates(ctx, request.Date)
 	if err != nil {
 		return models.GetExchangeCurrencies{}, err
 	}
 
-	return models.GetExchangeCurrencies{
+	path, method, err := usecase.findPath(graph, baseCurrency.Code, targetCurrency.Code)
+	if err != nil {
+		return models.GetExchangeCurrencies{}, err
+	}
+
+	side := request.Side
+	if side == "" {
+		side = models.SideMid
+	}
+
+	at := request.Date
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	warnings := make([]string, 0)
+	if stale := usecase.staleRates(path, at); len(stale) > 0 {
+		switch usecase.config.StalenessPolicy {
+		case models.StalenessRefuse:
+			return models.GetExchangeCurrencies{}, fmt.Errorf("%w: %s", models.ErrorExchangeRateStale, strings.Join(stale, "; "))
+		case models.StalenessFallback:
+			path, method, err = usecase.findPath(newRateGraph(usecase.freshRates(exchangerates, at)), baseCurrency.Code, targetCurrency.Code)
+			if errors.Is(err, models.ErrorExchangeRateNotFound) {
+				return models.GetExchangeCurrencies{}, fmt.Errorf("%w: %s; no fresh rates to fall back to", models.ErrorExchangeRateStale, strings.Join(stale, "; "))
+			}
+			if err != nil {
+				return models.GetExchangeCurrencies{}, err
+			}
+			for _, description := range stale {
+				warnings = append(warnings, description+", converted through fresh rates instead")
+			}
+		default:
+			warnings = append(warnings, stale...)
+		}
+	}
+
+	rounding := request.RoundingMode
+	if rounding == "" {
+		rounding = targetCurrency.RoundingMode
+	}
+
+	for i := range path {
+		path[i].Rate = sideRate(path[i], side)
+	}
+	rate := pathRate(path, side)
+
+	result := models.GetExchangeCurrencies{
 		BaseCurrency:    baseCurrency,
 		TargetCurrency:  targetCurrency,
-		Rate:            exchangerate.Rate,
-		Amount:          amount,
-		ConvertedAmount: amount * exchangerate.Rate,
-	}, nil
+		Rate:            rate,
+		Amount:          request.Amount,
+		ConvertedAmount: rounding.Round(request.Amount.Mul(rate), targetCurrency.MinorUnits),
+		RoundingMode:    rounding,
+		Side:            side,
+		Method:          method,
+		Path:            path,
+		Warnings:        warnings,
+	}
+	if !request.Date.IsZero() {
+		result.Date = &request.Date
+	}
+
+	rules, err := cache.feeRules(ctx)
+	if err != nil {
+		return models.GetExchangeCurrencies{}, err
+	}
+
+	rule, found := matchFeeRule(rules, baseCurrency.Code, targetCurrency.Code, request.ClientID, request.Amount)
+	if err := applyFeeRule(&result, rule, found); err != nil {
+		return models.GetExchangeCurrencies{}, err
+	}
+
+	return result, nil
 }
@@ -0,0 +1,105 @@
+package ratesync
+
+import (
+	"currencyservice/internal/models"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// minQuotesToReject is the number of quotes needed before any is rejected as
+// an outlier. With two quotes the median lies halfway between them, so both
+// or neither deviate and there is no majority telling which one is wrong.
+const minQuotesToReject = 3
+
+// computeConsensus agrees on a BASE→TARGET rate from the quotes of several
+// providers. Quotes for the reverse pair are inverted first. With at least
+// minQuotesToReject quotes, a quote is rejected when it deviates from the
+// median of all quotes by more than maxDeviation; the remaining ones are
+// combined according to method. Fewer quotes are all accepted, however far
+// apart they are.
+func computeConsensus(codeBaseCurrency, codeTargetCurrency string, quotes []models.ProviderQuote, weights map[string]int, method models.ConsensusMethod, maxDeviation decimal.Decimal, now time.Time) (models.RateConsensus, error) {
+	consensus := models.RateConsensus{
+		BaseCurrencyCode:   codeBaseCurrency,
+		TargetCurrencyCode: codeTargetCurrency,
+		Method:             method,
+		MaxDeviation:       maxDeviation,
+		ComputedAt:         now,
+		Contributions:      make([]models.ConsensusContribution, 0, len(quotes)),
+	}
+
+	values := make([]decimal.Decimal, 0, len(quotes))
+	for _, quote := range quotes {
+		rate := quote.Rate
+		if quote.BaseCurrencyCode != codeBaseCurrency {
+			if rate.IsZero() {
+				continue
+			}
+			rate = decimal.NewFromInt(1).DivRound(rate, models.RatePrecision)
+		}
+
+		weight, ok := weights[quote.Provider]
+		if !ok || weight <= 0 {
+			weight = 1
+		}
+
+		consensus.Contributions = append(consensus.Contributions, models.ConsensusContribution{
+			Provider:   quote.Provider,
+			Rate:       rate,
+			Weight:     weight,
+			ObservedAt: quote.ObservedAt,
+		})
+		values = append(values, rate)
+	}
+
+	if len(values) == 0 {
+		return models.RateConsensus{}, models.ErrorNoConsensus
+	}
+
+	median := medianOf(values)
+	accepted := make([]decimal.Decimal, 0, len(values))
+	weightedSum, totalWeight := decimal.Zero, decimal.Zero
+
+	for i := range consensus.Contributions {
+		contribution := &consensus.Contributions[i]
+		if !median.IsZero() {
+			contribution.Deviation = contribution.Rate.Sub(median).Abs().DivRound(median, models.RatePrecision)
+		}
+
+		if len(values) >= minQuotesToReject && contribution.Deviation.GreaterThan(maxDeviation) {
+			continue
+		}
+
+		contribution.Accepted = true
+		accepted = append(accepted, contribution.Rate)
+		weight := decimal.NewFromInt(int64(contribution.Weight))
+		weightedSum = weightedSum.Add(contribution.Rate.Mul(weight))
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	if len(accepted) == 0 {
+		return models.RateConsensus{}, models.ErrorNoConsensus
+	}
+
+	switch method {
+	case models.ConsensusWeighted:
+		consensus.Rate = weightedSum.DivRound(totalWeight, models.RatePrecision)
+	default:
+		consensus.Rate = medianOf(accepted)
+	}
+
+	return consensus, nil
+}
+
+func medianOf(values []decimal.Decimal) decimal.Decimal {
+	sorted := append([]decimal.Decimal(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LessThan(sorted[j]) })
+
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle]
+	}
+
+	return sorted[middle-1].Add(sorted[middle]).DivRound(decimal.NewFromInt(2), models.RatePrecision)
+}
@@ -0,0 +1,224 @@
+package ratesync
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"currencyservice/internal/provider"
+	"currencyservice/internal/repo/memory"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func quote(provider, base, target, rate string) models.ProviderQuote {
+	return models.ProviderQuote{
+		Provider:           provider,
+		BaseCurrencyCode:   base,
+		TargetCurrencyCode: target,
+		Rate:               decimal.RequireFromString(rate),
+	}
+}
+
+func TestComputeConsensus(t *testing.T) {
+	tests := []struct {
+		name         string
+		quotes       []models.ProviderQuote
+		weights      map[string]int
+		method       models.ConsensusMethod
+		maxDeviation string
+		want         string
+		wantRejected []string
+	}{
+		{
+			name:         "median of agreeing quotes",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.90"), quote("cbr", "USD", "EUR", "0.92"), quote("fixer", "USD", "EUR", "0.91")},
+			method:       models.ConsensusMedian,
+			maxDeviation: "0.05",
+			want:         "0.91",
+		},
+		{
+			name:         "median of an even number of quotes",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.90"), quote("cbr", "USD", "EUR", "0.91")},
+			method:       models.ConsensusMedian,
+			maxDeviation: "0.05",
+			want:         "0.905",
+		},
+		{
+			name:         "median without the outlier",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.90"), quote("cbr", "USD", "EUR", "0.91"), quote("fixer", "USD", "EUR", "0.92"), quote("broken", "USD", "EUR", "1.5")},
+			method:       models.ConsensusMedian,
+			maxDeviation: "0.05",
+			want:         "0.91",
+			wantRejected: []string{"broken"},
+		},
+		{
+			name:         "weighted mean without the outlier",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.90"), quote("cbr", "USD", "EUR", "0.92"), quote("broken", "USD", "EUR", "0.5")},
+			weights:      map[string]int{"ecb": 3, "cbr": 1, "broken": 5},
+			method:       models.ConsensusWeighted,
+			maxDeviation: "0.05",
+			want:         "0.905",
+			wantRejected: []string{"broken"},
+		},
+		{
+			name:         "missing and non-positive weights count once",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.90"), quote("cbr", "USD", "EUR", "0.93"), quote("fixer", "USD", "EUR", "0.93")},
+			weights:      map[string]int{"ecb": 4, "cbr": 0},
+			method:       models.ConsensusWeighted,
+			maxDeviation: "0.05",
+			want:         "0.91",
+		},
+		{
+			name:         "reverse quotes are inverted",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.8"), quote("cbr", "EUR", "USD", "1.25"), quote("fixer", "EUR", "USD", "1.28")},
+			method:       models.ConsensusMedian,
+			maxDeviation: "0.05",
+			want:         "0.8",
+		},
+		{
+			name:         "two disagreeing quotes are both accepted",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.9"), quote("cbr", "USD", "EUR", "1.1")},
+			method:       models.ConsensusMedian,
+			maxDeviation: "0.05",
+			want:         "1",
+		},
+		{
+			name:         "two disagreeing quotes are weighted",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.9"), quote("cbr", "USD", "EUR", "1.2")},
+			weights:      map[string]int{"ecb": 2, "cbr": 1},
+			method:       models.ConsensusWeighted,
+			maxDeviation: "0.05",
+			want:         "1",
+		},
+		{
+			name:         "a single quote is accepted",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "0.9")},
+			method:       models.ConsensusMedian,
+			maxDeviation: "0",
+			want:         "0.9",
+		},
+		{
+			name:         "deviation exactly at the limit is accepted",
+			quotes:       []models.ProviderQuote{quote("ecb", "USD", "EUR", "1"), quote("cbr", "USD", "EUR", "1.1"), quote("fixer", "USD", "EUR", "1.21")},
+			method:       models.ConsensusMedian,
+			maxDeviation: "0.1",
+			want:         "1.1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			consensus, err := computeConsensus("USD", "EUR", test.quotes, test.weights, test.method, decimal.RequireFromString(test.maxDeviation), time.Now())
+			if err != nil {
+				t.Fatalf("computeConsensus() error = %v", err)
+			}
+			if !consensus.Rate.Equal(decimal.RequireFromString(test.want)) {
+				t.Errorf("computeConsensus() rate = %s, want %s", consensus.Rate, test.want)
+			}
+
+			rejected := make([]string, 0)
+			for _, contribution := range consensus.Contributions {
+				if !contribution.Accepted {
+					rejected = append(rejected, contribution.Provider)
+				}
+			}
+			if len(rejected) != len(test.wantRejected) {
+				t.Fatalf("computeConsensus() rejected %v, want %v", rejected, test.wantRejected)
+			}
+			for i := range rejected {
+				if rejected[i] != test.wantRejected[i] {
+					t.Errorf("computeConsensus() rejected %v, want %v", rejected, test.wantRejected)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeConsensusNoConsensus(t *testing.T) {
+	tests := []struct {
+		name   string
+		quotes []models.ProviderQuote
+	}{
+		{name: "no quotes"},
+		{name: "only zero reverse quotes", quotes: []models.ProviderQuote{quote("ecb", "EUR", "USD", "0")}},
+		{
+			name: "every quote deviates",
+			quotes: []models.ProviderQuote{
+				quote("ecb", "USD", "EUR", "1"), quote("cbr", "USD", "EUR", "2"),
+				quote("fixer", "USD", "EUR", "3"), quote("oxr", "USD", "EUR", "4"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := computeConsensus("USD", "EUR", test.quotes, nil, models.ConsensusMedian, decimal.RequireFromString("0.1"), time.Now())
+			if !errors.Is(err, models.ErrorNoConsensus) {
+				t.Errorf("computeConsensus() error = %v, want %v", err, models.ErrorNoConsensus)
+			}
+		})
+	}
+}
+
+func TestApplyConsensusComparesQuotesForTheSameDate(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepo()
+	usecase := NewUsecase(nil, repo, nil, Config{
+		ConsensusMethod: models.ConsensusMedian,
+		MaxDeviation:    decimal.RequireFromString("0.02"),
+		MaxQuoteAge:     time.Hour,
+	})
+
+	march14 := time.Date(2024, time.March, 14, 0, 0, 0, 0, time.UTC)
+	march15 := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	apply := func(name, rate string, effectiveAt time.Time) decimal.Decimal {
+		t.Helper()
+
+		agreed, accepted, err := usecase.applyConsensus(ctx, name, provider.Rate{
+			BaseCurrencyCode:   "EUR",
+			TargetCurrencyCode: "USD",
+			Rate:               decimal.RequireFromString(rate),
+			EffectiveAt:        effectiveAt,
+		})
+		if err != nil {
+			t.Fatalf("%s %s on %s: %v", name, rate, effectiveAt.Format(time.DateOnly), err)
+		}
+		if !accepted {
+			t.Errorf("%s %s on %s was rejected", name, rate, effectiveAt.Format(time.DateOnly))
+		}
+
+		return agreed.Rate
+	}
+
+	if got := apply("ecb", "1.0925", march14); !got.Equal(decimal.RequireFromString("1.0925")) {
+		t.Errorf("ecb on March 14 agreed on %s, want its own 1.0925", got)
+	}
+	if got := apply("cbr", "1.0892", march15); !got.Equal(decimal.RequireFromString("1.0892")) {
+		t.Errorf("cbr on March 15 agreed on %s, want its own 1.0892 without the March 14 quote", got)
+	}
+	if got := apply("ecb", "1.0894", march15); !got.Equal(decimal.RequireFromString("1.0893")) {
+		t.Errorf("ecb on March 15 agreed on %s, want 1.0893 with the cbr quote", got)
+	}
+
+	// Backfilling March 14 neither replaces the March 15 quote of the
+	// provider nor the latest consensus.
+	apply("ecb", "1.0925", march14)
+
+	quotes, err := repo.GetProviderQuotes(ctx, "EUR", "USD", march15, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotes) != 2 || !quotes[1].Rate.Equal(decimal.RequireFromString("1.0894")) {
+		t.Errorf("March 15 quotes = %+v, want cbr 1.0892 and ecb 1.0894", quotes)
+	}
+
+	consensus, err := repo.GetRateConsensus(ctx, "EUR", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !consensus.EffectiveAt.Equal(march15) || !consensus.Rate.Equal(decimal.RequireFromString("1.0893")) {
+		t.Errorf("consensus = %s effective %s, want 1.0893 effective %s", consensus.Rate, consensus.EffectiveAt, march15)
+	}
+}
@@ -0,0 +1,20 @@
+package ratesync
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"time"
+)
+
+// Repository stores provider runs, quotes and consensus rates. It is
+// implemented by the SQLite backed currencies.Repo, the Postgres backed
+// postgres.Repo and the in-memory memory.Repo.
+type Repository interface {
+	AddProviderRun(ctx context.Context, run models.ProviderRun) (models.ProviderRun, error)
+	GetProviderRuns(ctx context.Context, provider string, limit int) ([]models.ProviderRun, error)
+
+	SaveProviderQuote(ctx context.Context, quote models.ProviderQuote) error
+	GetProviderQuotes(ctx context.Context, codeBaseCurrency, codeTargetCurrency string, effectiveAt, since time.Time) ([]models.ProviderQuote, error)
+	SaveRateConsensus(ctx context.Context, consensus models.RateConsensus) error
+	GetRateConsensus(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.RateConsensus, error)
+}
@@ -0,0 +1,318 @@
+package ratesync
+
+import (
+	"context"
+	"currencyservice/internal/models"
+	"currencyservice/internal/provider"
+	"currencyservice/internal/usecase/exchangerate"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Source is a rate provider together with how often it is polled and the
+// weight of its quotes in a weighted consensus.
+type Source struct {
+	Provider provider.RateProvider
+	Interval time.Duration
+	Priority int
+}
+
+type Config struct {
+	// ConsensusMethod combines the quotes of providers reporting the same pair.
+	ConsensusMethod models.ConsensusMethod
+	// MaxDeviation is the relative distance from the median beyond which a
+	// quote is rejected as an outlier, e.g. 0.02 for 2%.
+	MaxDeviation decimal.Decimal
+	// MaxQuoteAge is how long a quote takes part in the consensus after it
+	// was last reported.
+	MaxQuoteAge time.Duration
+}
+
+type Usecase struct {
+	exchangeUsecase *exchangerate.Usecase
+	repo            Repository
+	config          Config
+	sources         map[string]Source
+	weights         map[string]int
+	locks           map[string]*sync.Mutex
+	consensusLock   sync.Mutex
+}
+
+func NewUsecase(exchangeUsecase *exchangerate.Usecase, repo Repository, sources []Source, config Config) *Usecase {
+	usecase := &Usecase{
+		exchangeUsecase: exchangeUsecase,
+		repo:            repo,
+		config:          config,
+		sources:         make(map[string]Source, len(sources)),
+		weights:         make(map[string]int, len(sources)),
+		locks:           make(map[string]*sync.Mutex, len(sources)),
+	}
+
+	for _, source := range sources {
+		usecase.sources[source.Provider.Name()] = source
+		usecase.weights[source.Provider.Name()] = source.Priority
+		usecase.locks[source.Provider.Name()] = &sync.Mutex{}
+	}
+
+	return usecase
+}
+
+// Start polls every source in the background, first right away and then at
+// the source's interval, until ctx is cancelled.
+func (usecase *Usecase) Start(ctx context.Context) {
+	for _, source := range usecase.sources {
+		go usecase.poll(ctx, source)
+	}
+}
+
+func (usecase *Usecase) poll(ctx context.Context, source Source) {
+	ticker := time.NewTicker(source.Interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := usecase.RunProvider(ctx, source.Provider.Name())
+		if err != nil {
+			log.Printf("Failed to record %s provider run: %v", source.Provider.Name(), err)
+		} else if len(run.Errors) > 0 {
+			log.Printf("Provider %s finished with %d errors", run.Provider, len(run.Errors))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunProvider fetches the rates of the named provider, stores the ones that
+// changed and records the run. Errors of individual rates are collected in
+// the run instead of aborting it.
+func (usecase *Usecase) RunProvider(ctx context.Context, name string) (models.ProviderRun, error) {
+	source, ok := usecase.sources[name]
+	if !ok {
+		return models.ProviderRun{}, models.ErrorProviderNotFound
+	}
+
+	lock := usecase.locks[name]
+	lock.Lock()
+	defer lock.Unlock()
+
+	run := models.ProviderRun{
+		Provider:  name,
+		StartedAt: time.Now(),
+		Errors:    make([]string, 0),
+	}
+
+	rates, err := source.Provider.FetchRates(ctx)
+	if err != nil {
+		run.Errors = append(run.Errors, err.Error())
+	}
+	run.RowsFetched = len(rates)
+
+	for _, rate := range rates {
+		agreed, accepted, err := usecase.applyConsensus(ctx, name, rate)
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("%s%s: %v", rate.BaseCurrencyCode, rate.TargetCurrencyCode, err))
+			continue
+		}
+		if !accepted {
+			run.RowsRejected++
+		}
+
+		changed, err := usecase.upsertRate(ctx, agreed)
+		if err != nil {
+			run.Errors = append(run.Errors, fmt.Sprintf("%s%s: %v", rate.BaseCurrencyCode, rate.TargetCurrencyCode, err))
+			continue
+		}
+		if changed {
+			run.RowsChanged++
+
+			if _, err := usecase.exchangeUsecase.CheckExchangeRateWrite(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode); err != nil {
+				run.Errors = append(run.Errors, fmt.Sprintf("%s%s: %v", rate.BaseCurrencyCode, rate.TargetCurrencyCode, err))
+			}
+		}
+	}
+
+	run.FinishedAt = time.Now()
+
+	// The run is recorded even if the caller has gone away in the meantime,
+	// as its rates are already stored.
+	return usecase.repo.AddProviderRun(context.WithoutCancel(ctx), run)
+}
+
+func (usecase *Usecase) GetProviderRuns(ctx context.Context, provider string, limit int) ([]models.ProviderRun, error) {
+	runs, err := usecase.repo.GetProviderRuns(ctx, provider, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetRateConsensus returns the latest consensus of the pair with the
+// contribution of every provider.
+func (usecase *Usecase) GetRateConsensus(ctx context.Context, codeBaseCurrency, codeTargetCurrency string) (models.RateConsensus, error) {
+	consensus, err := usecase.repo.GetRateConsensus(ctx, codeBaseCurrency, codeTargetCurrency)
+	if err != nil {
+		return models.RateConsensus{}, err
+	}
+
+	return consensus, nil
+}
+
+// applyConsensus records the provider's quote and replaces its rate with the
+// consensus of all providers currently quoting the pair for the same
+// effective time, so a dated or historical rate is only compared with quotes
+// for its own date. It reports whether the provider's own quote was accepted
+// into the consensus.
+func (usecase *Usecase) applyConsensus(ctx context.Context, name string, rate provider.Rate) (provider.Rate, bool, error) {
+	usecase.consensusLock.Lock()
+	defer usecase.consensusLock.Unlock()
+
+	now := time.Now()
+	err := usecase.repo.SaveProviderQuote(ctx, models.ProviderQuote{
+		Provider:           name,
+		BaseCurrencyCode:   rate.BaseCurrencyCode,
+		TargetCurrencyCode: rate.TargetCurrencyCode,
+		Rate:               rate.Rate,
+		EffectiveAt:        rate.EffectiveAt,
+		ObservedAt:         now,
+	})
+	if err != nil {
+		return rate, false, err
+	}
+
+	quotes, err := usecase.repo.GetProviderQuotes(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.EffectiveAt, now.Add(-usecase.config.MaxQuoteAge))
+	if err != nil {
+		return rate, false, err
+	}
+
+	consensus, err := computeConsensus(rate.BaseCurrencyCode, rate.TargetCurrencyCode, quotes, usecase.weights, usecase.config.ConsensusMethod, usecase.config.MaxDeviation, now)
+	if err != nil {
+		return rate, false, err
+	}
+
+	consensus.EffectiveAt = rate.EffectiveAt
+	if consensus.EffectiveAt.IsZero() {
+		consensus.EffectiveAt = now
+	}
+
+	if err := usecase.repo.SaveRateConsensus(ctx, consensus); err != nil {
+		return rate, false, err
+	}
+
+	accepted := false
+	for _, contribution := range consensus.Contributions {
+		if contribution.Provider == name {
+			accepted = contribution.Accepted
+		}
+	}
+
+	rate.Rate = consensus.Rate
+	return rate, accepted, nil
+}
+
+// upsertRate creates or updates the pair unless the provider reports a value
+// that is already stored, so polling an unchanged feed is a no-op.
+func (usecase *Usecase) upsertRate(ctx context.Context, rate provider.Rate) (bool, error) {
+	if err := usecase.ensureCurrency(ctx, rate.BaseCurrencyCode, rate.BaseCurrencyName); err != nil {
+		return false, err
+	}
+
+	if err := usecase.ensureCurrency(ctx, rate.TargetCurrencyCode, rate.TargetCurrencyName); err != nil {
+		return false, err
+	}
+
+	current, err := usecase.exchangeUsecase.GetExchangeRateByCodesPair(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode)
+	if err != nil && !errors.Is(err, models.ErrorExchangeRateNotFound) {
+		return false, err
+	}
+
+	if errors.Is(err, models.ErrorExchangeRateNotFound) {
+		if rate.EffectiveAt.IsZero() {
+			return true, usecase.exchangeUsecase.CreateExchangeRate(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, decimal.Zero)
+		}
+
+		_, err := usecase.exchangeUsecase.ScheduleNewExchangeRate(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, rate.EffectiveAt)
+		return err == nil, err
+	}
+
+	if rate.EffectiveAt.IsZero() {
+		if current.Rate.Equal(rate.Rate) {
+			return false, usecase.exchangeUsecase.ConfirmExchangeRate(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode)
+		}
+
+		return true, usecase.exchangeUsecase.UpdateExchangeRate(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, decimal.NullDecimal{})
+	}
+
+	known, err := usecase.isKnownRate(ctx, rate)
+	if err != nil || known {
+		return false, err
+	}
+
+	_, err = usecase.exchangeUsecase.ScheduleExchangeRateUpdate(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode, rate.Rate, rate.EffectiveAt)
+	return err == nil, err
+}
+
+// isKnownRate reports whether the same value is already recorded, or
+// scheduled, for the rate's effective time.
+func (usecase *Usecase) isKnownRate(ctx context.Context, rate provider.Rate) (bool, error) {
+	effectiveAt := time.Unix(rate.EffectiveAt.Unix(), 0)
+
+	history, err := usecase.exchangeUsecase.GetExchangeRateHistory(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode, effectiveAt, effectiveAt)
+	if err != nil {
+		return false, err
+	}
+
+	for _, historical := range history {
+		if historical.Rate.Equal(rate.Rate) {
+			return true, nil
+		}
+	}
+
+	scheduledRates, err := usecase.exchangeUsecase.GetScheduledExchangeRates(ctx, rate.BaseCurrencyCode, rate.TargetCurrencyCode)
+	if err != nil {
+		return false, err
+	}
+
+	for _, scheduled := range scheduledRates {
+		if scheduled.EffectiveAt.Equal(effectiveAt) && scheduled.Rate.Equal(rate.Rate) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ensureCurrency creates a currency reported by a provider that is not
+// known yet, falling back to its code where the provider gives no name.
+func (usecase *Usecase) ensureCurrency(ctx context.Context, code, name string) error {
+	_, err := usecase.exchangeUsecase.GetCurrency(ctx, code)
+	if !errors.Is(err, models.ErrorCurrencyNotFound) {
+		return err
+	}
+
+	if name == "" {
+		name = code
+	}
+
+	err = usecase.exchangeUsecase.CreateNewCurrency(ctx, models.Currency{
+		Code:         code,
+		FullName:     name,
+		Sign:         code,
+		MinorUnits:   models.DefaultMinorUnits(code),
+		RoundingMode: models.DefaultRoundingMode,
+	})
+	// Another writer may have created the currency since it was looked up.
+	if errors.Is(err, models.ErrorCurrencyAlreadyExists) {
+		return nil
+	}
+
+	return err
+}